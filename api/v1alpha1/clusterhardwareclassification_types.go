@@ -0,0 +1,66 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterHardwareClassificationSpec defines the desired state of ClusterHardwareClassification
+type ClusterHardwareClassificationSpec struct {
+	HardwareClassificationSpec `json:",inline"`
+
+	// +optional
+	// NamespaceSelector selects the namespaces whose BareMetalHosts
+	// the profile applies to. An empty selector selects all
+	// namespaces.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=chwc
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ProfileMatchStatus",type="string",JSONPath=".status.profileMatchStatus",description="Profile Match Status"
+// +kubebuilder:printcolumn:name="MatchedHosts",type="integer",JSONPath=".status.matchedCount",description="Total Matched hosts."
+// +kubebuilder:printcolumn:name="UnmatchedHosts",type="integer",JSONPath=".status.unmatchedCount",description="Total Unmatched hosts."
+// +kubebuilder:printcolumn:name="AvailableHosts",type="integer",JSONPath=".status.availableCount",description="Total matched hosts ready or available without consumer."
+// +kubebuilder:printcolumn:name="ConsumedHosts",type="integer",priority=1,JSONPath=".status.consumedCount",description="Total matched hosts with a consumer."
+// +kubebuilder:printcolumn:name="EligibleHosts",type="integer",priority=1,JSONPath=".status.eligibleCount",description="Total hosts matching the profile."
+// +kubebuilder:printcolumn:name="LabelledHosts",type="integer",priority=1,JSONPath=".status.labelledCount",description="Total hosts labelled for the profile."
+
+// ClusterHardwareClassification is the Schema for the
+// clusterhardwareclassifications API. It classifies the BareMetalHosts
+// of all the namespaces selected by its namespaceSelector.
+type ClusterHardwareClassification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterHardwareClassificationSpec `json:"spec,omitempty"`
+	Status HardwareClassificationStatus      `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterHardwareClassificationList contains a list of ClusterHardwareClassification
+type ClusterHardwareClassificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterHardwareClassification `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterHardwareClassification{}, &ClusterHardwareClassificationList{})
+}
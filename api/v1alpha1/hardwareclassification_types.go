@@ -16,6 +16,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -36,6 +37,103 @@ type HardwareClassificationSpec struct {
 
 	// HardwareCharacteristics defines expected hardware configurations for Cpu, Disk, Nic, Ram, SystemVendor and Firmware.
 	HardwareCharacteristics HardwareCharacteristics `json:"hardwareCharacteristics,omitempty"`
+
+	// +optional
+	// TemplateRef names a HardwareClassificationTemplate providing
+	// the characteristics of the profile. The characteristics set in
+	// HardwareCharacteristics override the ones of the template.
+	TemplateRef *TemplateReference `json:"templateRef,omitempty"`
+
+	// Scoring enables matching hosts on a weighted fit score instead
+	// of requiring every characteristic to match.
+	// +optional
+	Scoring *Scoring `json:"scoring,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// MaxHosts is the maximum number of matching hosts labelled for
+	// the profile. When more hosts match, the SelectionPolicy decides
+	// which of them are labelled.
+	MaxHosts int `json:"maxHosts,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// MinHosts is the number of matching hosts the profile is
+	// expected to have. It is only reported through the status
+	// conditions and does not change which hosts are labelled.
+	MinHosts int `json:"minHosts,omitempty"`
+	// +optional
+	// SelectionPolicy decides which matching hosts are labelled when
+	// more hosts than MaxHosts match. Defaults to Oldest.
+	SelectionPolicy SelectionPolicy `json:"selectionPolicy,omitempty"`
+	// +optional
+	// UnlabelPolicy decides whether the label is removed from hosts
+	// which no longer match the profile. Defaults to Always.
+	UnlabelPolicy UnlabelPolicy `json:"unlabelPolicy,omitempty"`
+}
+
+// TemplateReference identifies a HardwareClassificationTemplate.
+type TemplateReference struct {
+	// Name of the template.
+	Name string `json:"name"`
+	// +optional
+	// Namespace of the template. Defaults to the namespace of the
+	// profile, and is required for cluster profiles. Namespaced
+	// profiles can only reference templates of their own namespace.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SelectionPolicy is the order in which matching hosts are selected
+// for labelling when the profile limits the number of hosts.
+// +kubebuilder:validation:Enum=Oldest;Name;BestScore
+type SelectionPolicy string
+
+const (
+	// SelectionPolicyOldest selects the hosts created first.
+	SelectionPolicyOldest SelectionPolicy = "Oldest"
+	// SelectionPolicyName selects the hosts in name order.
+	SelectionPolicyName SelectionPolicy = "Name"
+	// SelectionPolicyBestScore selects the hosts with the highest
+	// fit score, in name order when the scores are equal.
+	SelectionPolicyBestScore SelectionPolicy = "BestScore"
+)
+
+// Scoring contains the settings for the score based classification
+type Scoring struct {
+	// MinimumScore is the lowest fit score, in percent, a host needs
+	// to be considered a match for the profile.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinimumScore int `json:"minimumScore"`
+	// +optional
+	// Weights of the characteristics contributing to the score.
+	Weights ScoringWeights `json:"weights,omitempty"`
+	// +optional
+	// AnnotateHosts records the score of the profile on each host
+	// with an annotation.
+	AnnotateHosts bool `json:"annotateHosts,omitempty"`
+}
+
+// ScoringWeights contains the weight of each characteristic. A weight
+// which is not set defaults to 1.
+type ScoringWeights struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Cpu int `json:"cpu,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Disk int `json:"disk,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Nic int `json:"nic,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Ram int `json:"ram,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	SystemVendor int `json:"systemVendor,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Firmware int `json:"firmware,omitempty"`
 }
 
 // HardwareCharacteristics details to match with the host
@@ -77,12 +175,32 @@ type BIOS struct {
 	MajorVersion string `json:"majorVersion,omitempty"`
 }
 
+// DiskType is the kind of a disk, derived from the rotational flag and
+// the name of the disk reported by the host.
+// +kubebuilder:validation:Enum=HDD;SSD;NVMe
+type DiskType string
+
+const (
+	// DiskTypeHDD is a rotational disk.
+	DiskTypeHDD DiskType = "HDD"
+	// DiskTypeSSD is a non-rotational disk which is not NVMe.
+	DiskTypeSSD DiskType = "SSD"
+	// DiskTypeNVMe is a NVMe disk.
+	DiskTypeNVMe DiskType = "NVMe"
+)
+
 // DiskSelector contains disk details extracted from hardware profile
 type DiskSelector struct {
 	// +optional
+	// HCTL selects the disks at the SCSI location, any location when unset
 	HCTL string `json:"hctl,omitempty"`
 	// +optional
-	Rotational bool `json:"rotational,omitempty"`
+	// Rotational selects rotational disks when true and
+	// non-rotational disks when false. Both are selected when unset.
+	Rotational *bool `json:"rotational,omitempty"`
+	// +optional
+	// Type selects the disks of the given type, any type when unset
+	Type DiskType `json:"type,omitempty"`
 }
 
 // Cpu contains cpu details extracted from the hardware profile
@@ -127,8 +245,12 @@ type Disk struct {
 	// +kubebuilder:validation:Minimum=1
 	// MinimumIndividualSizeGB should be greater than 0
 	// Ex. MinimumIndividualSizeGB > 0
+	// Deprecated: use MinimumIndividualSize, which takes precedence.
 	MinimumIndividualSizeGB int64 `json:"minimumIndividualSizeGB,omitempty"`
 	// +optional
+	// MinimumIndividualSize is the minimum size of each disk, e.g. 1.92T
+	MinimumIndividualSize *resource.Quantity `json:"minimumIndividualSize,omitempty"`
+	// +optional
 	// +kubebuilder:validation:Minimum=1
 	// MaximumCount of disk should be greater than 0 and greater than MinimumCount
 	// Ex. MaximumCount > 0 && MaximumCount > MinimumCount
@@ -137,8 +259,19 @@ type Disk struct {
 	// +kubebuilder:validation:Minimum=1
 	// Maximum individual size should be greater than 0 and greater than MinimumIndividualSizeGB
 	// Ex. MaximumIndividualSizeGB > 0 && MaximumIndividualSizeGB > MinimumIndividualSizeGB
+	// Deprecated: use MaximumIndividualSize, which takes precedence.
 	MaximumIndividualSizeGB int64 `json:"maximumIndividualSizeGB,omitempty"`
 	// +optional
+	// MaximumIndividualSize is the maximum size of each disk, e.g. 2T
+	MaximumIndividualSize *resource.Quantity `json:"maximumIndividualSize,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// SizeTolerancePercent widens the disk size range by the given
+	// percentage, to accept disks reporting slightly less or more
+	// capacity than advertised by the vendor.
+	SizeTolerancePercent int `json:"sizeTolerancePercent,omitempty"`
+	// +optional
 	DiskSelector []DiskSelector `json:"diskSelector,omitempty"`
 }
 
@@ -170,12 +303,29 @@ type Ram struct {
 	// +kubebuilder:validation:Minimum=1
 	// MinimumSizeGB of Ram should be greater than 0
 	// Ex. MinimumSizeGB > 0
+	// The size is compared in GiB.
+	// Deprecated: use MinimumSize, which takes precedence.
 	MinimumSizeGB int `json:"minimumSizeGB,omitempty"`
 	// +optional
+	// MinimumSize is the minimum RAM size, e.g. 384Gi
+	MinimumSize *resource.Quantity `json:"minimumSize,omitempty"`
+	// +optional
 	// +kubebuilder:validation:Minimum=1
 	// MaximumSizeGB should be greater than 0 or greater than MinimumSizeGB
 	// Ex. MaximumSizeGB > 0 && MaximumSizeGB > MinimumSizeGB
+	// The size is compared in GiB.
+	// Deprecated: use MaximumSize, which takes precedence.
 	MaximumSizeGB int `json:"maximumSizeGB,omitempty"`
+	// +optional
+	// MaximumSize is the maximum RAM size, e.g. 512Gi
+	MaximumSize *resource.Quantity `json:"maximumSize,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// SizeTolerancePercent widens the RAM size range by the given
+	// percentage, to accept hosts reporting slightly less memory than
+	// installed.
+	SizeTolerancePercent int `json:"sizeTolerancePercent,omitempty"`
 }
 
 // ProfileMatchStatus represents the state of the HardwareClassification
@@ -212,6 +362,10 @@ const (
 	// ProfileMisConfigured is an error condition occurring when the
 	// extracted profile is empty.
 	ProfileMisConfigured ErrorType = "Empty Profile Error"
+	// ProfileNameReserved is an error condition occurring when the
+	// profile is named unclassified, like the label of the hosts
+	// matching no profile.
+	ProfileNameReserved ErrorType = "reserved profile name error"
 	// Empty is an empty error
 	Empty ErrorType = ""
 )
@@ -289,6 +443,96 @@ const (
 	NOError string = ""
 )
 
+const (
+	// ConditionMinHostsAvailable is the condition type reporting
+	// whether at least MinHosts hosts match the profile.
+	ConditionMinHostsAvailable string = "MinHostsAvailable"
+
+	// ReasonEnoughHosts is the reason used when enough hosts match
+	// the profile.
+	ReasonEnoughHosts string = "EnoughHosts"
+	// ReasonInsufficientHosts is the reason used when fewer than
+	// MinHosts hosts match the profile.
+	ReasonInsufficientHosts string = "InsufficientHosts"
+
+	// ConditionHardwareDrift is the condition type reporting whether
+	// the hardware of a host labelled for the profile changed since
+	// it was classified.
+	ConditionHardwareDrift string = "HardwareDrift"
+
+	// ReasonNoHardwareDrift is the reason used when no hardware
+	// change was detected.
+	ReasonNoHardwareDrift string = "NoHardwareDrift"
+	// ReasonHardwareChanged is the reason used when the hardware of
+	// at least one host changed.
+	ReasonHardwareChanged string = "HardwareChanged"
+
+	// ConditionTemplateResolved is the condition type reporting
+	// whether the template referenced by the profile was found.
+	ConditionTemplateResolved string = "TemplateResolved"
+
+	// ReasonTemplateFound is the reason used when the template was
+	// merged into the effective characteristics.
+	ReasonTemplateFound string = "TemplateFound"
+	// ReasonTemplateNotFound is the reason used when the template
+	// could not be loaded.
+	ReasonTemplateNotFound string = "TemplateNotFound"
+	// ReasonTemplateNamespaceNotAllowed is the reason used when a
+	// namespaced profile references a template of another namespace.
+	ReasonTemplateNamespaceNotAllowed string = "TemplateNamespaceNotAllowed"
+)
+
+// UnlabelPolicy controls when the label is removed from a host which
+// no longer matches the profile.
+// +kubebuilder:validation:Enum=Always;OnlyIfNotProvisioned;Never
+type UnlabelPolicy string
+
+const (
+	// UnlabelPolicyAlways removes the label as soon as the host no
+	// longer matches.
+	UnlabelPolicyAlways UnlabelPolicy = "Always"
+	// UnlabelPolicyOnlyIfNotProvisioned keeps the label on hosts
+	// which are provisioned or consumed.
+	UnlabelPolicyOnlyIfNotProvisioned UnlabelPolicy = "OnlyIfNotProvisioned"
+	// UnlabelPolicyNever keeps the label on all hosts until the
+	// profile is deleted.
+	UnlabelPolicyNever UnlabelPolicy = "Never"
+)
+
+// HostScore is the fit score of a host for a profile
+type HostScore struct {
+	// Name of the BareMetalHost
+	Name string `json:"name"`
+	// Score of the host in percent
+	Score int `json:"score"`
+}
+
+// MaxHostScores is the maximum number of host scores reported in the
+// status of a profile.
+const MaxHostScores = 20
+
+// FailingHost describes a host in error state
+type FailingHost struct {
+	// Name of the BareMetalHost
+	Name string `json:"name"`
+	// ErrorType reported by the host
+	ErrorType string `json:"errorType,omitempty"`
+	// ErrorMessage reported by the host
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// MaxFailingHosts is the maximum number of failing hosts reported in
+// the status of a profile.
+const MaxFailingHosts = 20
+
+// NoProvisioningState is the key counting the matched hosts which do
+// not report a provisioning state yet.
+const NoProvisioningState = "none"
+
+// UnknownErrorType is the key counting the hosts in error state which
+// do not report an error type.
+const UnknownErrorType = "unknown"
+
 // HardwareClassificationStatus defines the observed state of HardwareClassification
 type HardwareClassificationStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -318,17 +562,51 @@ type HardwareClassificationStatus struct {
 	PreparationErrorHosts PreparationErrorHosts `json:"preparationErrorHosts,omitempty"`
 	// The count of hosts in Detach error state
 	DetachErrorHosts DetachErrorHosts `json:"detachErrorHosts,omitempty"`
+	// The count of hosts in error state per error type reported by
+	// the hosts
+	ErrorHostsByType map[string]int `json:"errorHostsByType,omitempty"`
+	// The hosts in error state in name order, at most MaxFailingHosts
+	FailingHosts []FailingHost `json:"failingHosts,omitempty"`
 	// The last error message reported by the hardwareclassification system
 	ErrorMessage string `json:"errorMessage,omitempty"`
+	// The best scoring hosts, highest score first, when scoring is enabled
+	HostScores []HostScore `json:"hostScores,omitempty"`
+	// The count of hosts matching the profile
+	EligibleCount int `json:"eligibleCount,omitempty"`
+	// The count of hosts labelled for the profile
+	LabelledCount int `json:"labelledCount,omitempty"`
+	// The names of the hosts selected for labelling when the profile
+	// sets MaxHosts
+	SelectedHosts []string `json:"selectedHosts,omitempty"`
+	// The names of the hosts which are labelled but no longer match
+	// the profile
+	StaleHosts []string `json:"staleHosts,omitempty"`
+	// The count of hosts labelled for the profile per provisioning
+	// state, hosts without state are counted as "none"
+	MatchedHostsByState map[string]int `json:"matchedHostsByState,omitempty"`
+	// The count of hosts labelled for the profile which have a consumer
+	ConsumedCount int `json:"consumedCount,omitempty"`
+	// The count of hosts labelled for the profile which are ready or
+	// available and have no consumer
+	AvailableCount int `json:"availableCount,omitempty"`
+	// The characteristics used to classify hosts, merged from the
+	// template and the profile, when the profile sets TemplateRef
+	EffectiveHardwareCharacteristics *HardwareCharacteristics `json:"effectiveHardwareCharacteristics,omitempty"`
+	// Conditions describe the state of the profile
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:shortName=hwc;hc
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="ProfileMatchStatus",type="string",JSONPath=".status.profileMatchStatus",description="Profile Match Status"
 // +kubebuilder:printcolumn:name="MatchedHosts",type="integer",JSONPath=".status.matchedCount",description="Total Matched hosts."
 // +kubebuilder:printcolumn:name="UnmatchedHosts",type="integer",JSONPath=".status.unmatchedCount",description="Total Unmatched hosts."
 // +kubebuilder:printcolumn:name="ErrorHosts",type="integer",JSONPath=".status.errorHosts",description="Total error hosts."
+// +kubebuilder:printcolumn:name="AvailableHosts",type="integer",JSONPath=".status.availableCount",description="Total matched hosts ready or available without consumer."
+// +kubebuilder:printcolumn:name="ConsumedHosts",type="integer",priority=1,JSONPath=".status.consumedCount",description="Total matched hosts with a consumer."
 // +kubebuilder:printcolumn:name="RegistrationErrorHosts",type="integer",priority=1,JSONPath=".status.registrationErrorHosts",description="Total hosts in Registration error state."
 // +kubebuilder:printcolumn:name="IntrospectionErrorHosts",type="integer",priority=1,JSONPath=".status.introspectionErrorHosts",description="Total hosts in Introspection error state."
 // +kubebuilder:printcolumn:name="ProvisioningErrorHosts",type="integer",priority=1,JSONPath=".status.provisioningErrorHosts",description="Total hosts in Provisioning error state."
@@ -336,6 +614,8 @@ type HardwareClassificationStatus struct {
 // +kubebuilder:printcolumn:name="ProvisionedRegistrationErrorHosts",priority=1,type="integer",JSONPath=".status.provisionedRegistrationErrorHosts",description="Total hosts in Provisioned Registration error state."
 // +kubebuilder:printcolumn:name="PreparationErrorHosts",type="integer",priority=1,JSONPath=".status.preparationErrorHosts",description="Total hosts in Preparation error state."
 // +kubebuilder:printcolumn:name="DetachErrorHosts",type="integer",priority=1,JSONPath=".status.detachErrorHosts",description="Total hosts in Detach error state."
+// +kubebuilder:printcolumn:name="EligibleHosts",type="integer",priority=1,JSONPath=".status.eligibleCount",description="Total hosts matching the profile."
+// +kubebuilder:printcolumn:name="LabelledHosts",type="integer",priority=1,JSONPath=".status.labelledCount",description="Total hosts labelled for the profile."
 // +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.errorMessage",description="Most recent error"
 
 // HardwareClassification is the Schema for the hardwareclassifications API
@@ -0,0 +1,54 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HardwareClassificationTemplateSpec defines the characteristics
+// shared by the profiles referencing the template
+type HardwareClassificationTemplateSpec struct {
+	// HardwareCharacteristics defines expected hardware configurations for Cpu, Disk, Nic, Ram, SystemVendor and Firmware.
+	HardwareCharacteristics HardwareCharacteristics `json:"hardwareCharacteristics,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=hwct
+
+// HardwareClassificationTemplate is the Schema for the
+// hardwareclassificationtemplates API. HardwareClassifications
+// reference it through spec.templateRef and override its
+// characteristics field by field.
+type HardwareClassificationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HardwareClassificationTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HardwareClassificationTemplateList contains a list of HardwareClassificationTemplate
+type HardwareClassificationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HardwareClassificationTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HardwareClassificationTemplate{}, &HardwareClassificationTemplateList{})
+}
@@ -0,0 +1,190 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HardwareInventoryName is the name of the HardwareInventory maintained
+// in every namespace with BareMetalHosts.
+const HardwareInventoryName = "hardware-inventory"
+
+// HardwareInventorySpec is empty, the inventory is maintained by the
+// controller.
+type HardwareInventorySpec struct {
+}
+
+// HardwareInventoryStatus groups the BareMetalHosts of the namespace by
+// hardware
+type HardwareInventoryStatus struct {
+	// TotalHosts is the number of BareMetalHosts in the namespace
+	TotalHosts int `json:"totalHosts"`
+	// UninspectedHosts is the number of BareMetalHosts without
+	// hardware details, which are in no group
+	UninspectedHosts int `json:"uninspectedHosts"`
+	// GroupCount is the number of groups of identical hardware
+	GroupCount int `json:"groupCount"`
+	// +optional
+	// Groups are the groups of hosts with identical hardware, the
+	// largest first
+	Groups []HardwareGroup `json:"groups,omitempty"`
+	// +optional
+	// Coverage reports how the profiles applying to the namespace
+	// classify its hosts
+	Coverage *ProfileCoverage `json:"coverage,omitempty"`
+}
+
+// ProfileCoverage reports the hosts matching no profile or several
+// profiles, and the profiles subsumed by another one. Namespaced
+// profiles are named by their name, cluster profiles by
+// ClusterHardwareClassification/<name>.
+type ProfileCoverage struct {
+	// ProfileCount is the number of profiles applying to the namespace
+	ProfileCount int `json:"profileCount"`
+	// UnclassifiedCount is the number of inspected hosts matching no
+	// profile
+	UnclassifiedCount int `json:"unclassifiedCount"`
+	// +optional
+	// UnclassifiedHosts are the names of the inspected hosts matching
+	// no profile
+	UnclassifiedHosts []string `json:"unclassifiedHosts,omitempty"`
+	// OverlappingCount is the number of hosts matching several
+	// profiles
+	OverlappingCount int `json:"overlappingCount"`
+	// +optional
+	// OverlappingHosts are the hosts matching several profiles
+	OverlappingHosts []HostOverlap `json:"overlappingHosts,omitempty"`
+	// +optional
+	// SubsumedProfiles are the profiles whose hosts all match another
+	// profile matching more hosts
+	SubsumedProfiles []ProfileSubsumption `json:"subsumedProfiles,omitempty"`
+}
+
+// HostOverlap is a host matching several profiles
+type HostOverlap struct {
+	Host     string   `json:"host"`
+	Profiles []string `json:"profiles"`
+}
+
+// SubsumptionBasis tells how a profile was found to be subsumed
+// +kubebuilder:validation:Enum=Characteristics;Hosts
+type SubsumptionBasis string
+
+const (
+	// SubsumptionBasisCharacteristics means the rules of the other
+	// profile are strictly looser, so it matches any host the profile
+	// matches.
+	SubsumptionBasisCharacteristics SubsumptionBasis = "Characteristics"
+	// SubsumptionBasisHosts means the hosts matching the profile are
+	// some of the hosts matching the other profile, although their
+	// rules do not show it.
+	SubsumptionBasisHosts SubsumptionBasis = "Hosts"
+)
+
+// ProfileSubsumption is a profile strictly subsumed by another one
+type ProfileSubsumption struct {
+	Profile    string           `json:"profile"`
+	SubsumedBy string           `json:"subsumedBy"`
+	Basis      SubsumptionBasis `json:"basis"`
+}
+
+// HardwareGroup is a set of hosts with identical hardware
+type HardwareGroup struct {
+	// Hardware is the hardware shared by the hosts
+	Hardware HardwareSignature `json:"hardware"`
+	// Count is the number of hosts in the group
+	Count int `json:"count"`
+	// Hosts are the names of the hosts in the group, in name order
+	Hosts []string `json:"hosts"`
+}
+
+// HardwareSignature describes the hardware compared when grouping
+// hosts. Serial numbers, addresses and firmware versions are left out.
+type HardwareSignature struct {
+	// +optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+	// +optional
+	ProductName string       `json:"productName,omitempty"`
+	CPU         CPUSignature `json:"cpu"`
+	// RAMMebibytes is the size of the RAM in MiB
+	RAMMebibytes int `json:"ramMebibytes"`
+	// +optional
+	// Disks are the disks of the hosts, grouped by type and size
+	Disks []DiskSignature `json:"disks,omitempty"`
+	// +optional
+	// NICs are the network interfaces of the hosts, grouped by model
+	// and speed
+	NICs []NICSignature `json:"nics,omitempty"`
+}
+
+// CPUSignature describes the CPUs of a host
+type CPUSignature struct {
+	// +optional
+	Arch string `json:"arch,omitempty"`
+	// +optional
+	Model string `json:"model,omitempty"`
+	Count int    `json:"count"`
+}
+
+// DiskSignature describes the disks of a host of one type and size
+type DiskSignature struct {
+	Type      DiskType `json:"type"`
+	SizeBytes int64    `json:"sizeBytes"`
+	Count     int      `json:"count"`
+}
+
+// NICSignature describes the network interfaces of a host of one
+// model and speed
+type NICSignature struct {
+	// +optional
+	Model     string `json:"model,omitempty"`
+	SpeedGbps int    `json:"speedGbps"`
+	Count     int    `json:"count"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=hwi
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Hosts",type="integer",JSONPath=".status.totalHosts",description="Total hosts in the namespace."
+// +kubebuilder:printcolumn:name="Groups",type="integer",JSONPath=".status.groupCount",description="Groups of hosts with identical hardware."
+// +kubebuilder:printcolumn:name="UnclassifiedHosts",type="integer",JSONPath=".status.coverage.unclassifiedCount",description="Total inspected hosts matching no profile."
+// +kubebuilder:printcolumn:name="OverlappingHosts",type="integer",JSONPath=".status.coverage.overlappingCount",description="Total hosts matching several profiles."
+// +kubebuilder:printcolumn:name="UninspectedHosts",type="integer",JSONPath=".status.uninspectedHosts",description="Total hosts without hardware details."
+
+// HardwareInventory is the Schema for the hardwareinventories API. The
+// controller maintains one, named hardware-inventory, in every
+// namespace with BareMetalHosts.
+type HardwareInventory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HardwareInventorySpec   `json:"spec,omitempty"`
+	Status HardwareInventoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HardwareInventoryList contains a list of HardwareInventory
+type HardwareInventoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HardwareInventory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HardwareInventory{}, &HardwareInventoryList{})
+}
@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -39,6 +40,101 @@ func (in *BIOS) DeepCopy() *BIOS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUSignature) DeepCopyInto(out *CPUSignature) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUSignature.
+func (in *CPUSignature) DeepCopy() *CPUSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterHardwareClassification) DeepCopyInto(out *ClusterHardwareClassification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHardwareClassification.
+func (in *ClusterHardwareClassification) DeepCopy() *ClusterHardwareClassification {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterHardwareClassification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterHardwareClassification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterHardwareClassificationList) DeepCopyInto(out *ClusterHardwareClassificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterHardwareClassification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHardwareClassificationList.
+func (in *ClusterHardwareClassificationList) DeepCopy() *ClusterHardwareClassificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterHardwareClassificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterHardwareClassificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterHardwareClassificationSpec) DeepCopyInto(out *ClusterHardwareClassificationSpec) {
+	*out = *in
+	in.HardwareClassificationSpec.DeepCopyInto(&out.HardwareClassificationSpec)
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHardwareClassificationSpec.
+func (in *ClusterHardwareClassificationSpec) DeepCopy() *ClusterHardwareClassificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterHardwareClassificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Cpu) DeepCopyInto(out *Cpu) {
 	*out = *in
@@ -57,10 +153,22 @@ func (in *Cpu) DeepCopy() *Cpu {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Disk) DeepCopyInto(out *Disk) {
 	*out = *in
+	if in.MinimumIndividualSize != nil {
+		in, out := &in.MinimumIndividualSize, &out.MinimumIndividualSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaximumIndividualSize != nil {
+		in, out := &in.MaximumIndividualSize, &out.MaximumIndividualSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	if in.DiskSelector != nil {
 		in, out := &in.DiskSelector, &out.DiskSelector
 		*out = make([]DiskSelector, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -77,6 +185,11 @@ func (in *Disk) DeepCopy() *Disk {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiskSelector) DeepCopyInto(out *DiskSelector) {
 	*out = *in
+	if in.Rotational != nil {
+		in, out := &in.Rotational, &out.Rotational
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSelector.
@@ -89,6 +202,36 @@ func (in *DiskSelector) DeepCopy() *DiskSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSignature) DeepCopyInto(out *DiskSignature) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSignature.
+func (in *DiskSignature) DeepCopy() *DiskSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailingHost) DeepCopyInto(out *FailingHost) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailingHost.
+func (in *FailingHost) DeepCopy() *FailingHost {
+	if in == nil {
+		return nil
+	}
+	out := new(FailingHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Firmware) DeepCopyInto(out *Firmware) {
 	*out = *in
@@ -126,7 +269,7 @@ func (in *HardwareCharacteristics) DeepCopyInto(out *HardwareCharacteristics) {
 	if in.Ram != nil {
 		in, out := &in.Ram, &out.Ram
 		*out = new(Ram)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.SystemVendor != nil {
 		in, out := &in.SystemVendor, &out.SystemVendor
@@ -156,7 +299,7 @@ func (in *HardwareClassification) DeepCopyInto(out *HardwareClassification) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassification.
@@ -213,6 +356,16 @@ func (in *HardwareClassificationList) DeepCopyObject() runtime.Object {
 func (in *HardwareClassificationSpec) DeepCopyInto(out *HardwareClassificationSpec) {
 	*out = *in
 	in.HardwareCharacteristics.DeepCopyInto(&out.HardwareCharacteristics)
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(TemplateReference)
+		**out = **in
+	}
+	if in.Scoring != nil {
+		in, out := &in.Scoring, &out.Scoring
+		*out = new(Scoring)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationSpec.
@@ -228,6 +381,52 @@ func (in *HardwareClassificationSpec) DeepCopy() *HardwareClassificationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HardwareClassificationStatus) DeepCopyInto(out *HardwareClassificationStatus) {
 	*out = *in
+	if in.ErrorHostsByType != nil {
+		in, out := &in.ErrorHostsByType, &out.ErrorHostsByType
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FailingHosts != nil {
+		in, out := &in.FailingHosts, &out.FailingHosts
+		*out = make([]FailingHost, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostScores != nil {
+		in, out := &in.HostScores, &out.HostScores
+		*out = make([]HostScore, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectedHosts != nil {
+		in, out := &in.SelectedHosts, &out.SelectedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaleHosts != nil {
+		in, out := &in.StaleHosts, &out.StaleHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MatchedHostsByState != nil {
+		in, out := &in.MatchedHostsByState, &out.MatchedHostsByState
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EffectiveHardwareCharacteristics != nil {
+		in, out := &in.EffectiveHardwareCharacteristics, &out.EffectiveHardwareCharacteristics
+		*out = new(HardwareCharacteristics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationStatus.
@@ -240,6 +439,278 @@ func (in *HardwareClassificationStatus) DeepCopy() *HardwareClassificationStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassificationTemplate) DeepCopyInto(out *HardwareClassificationTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationTemplate.
+func (in *HardwareClassificationTemplate) DeepCopy() *HardwareClassificationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassificationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareClassificationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassificationTemplateList) DeepCopyInto(out *HardwareClassificationTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HardwareClassificationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationTemplateList.
+func (in *HardwareClassificationTemplateList) DeepCopy() *HardwareClassificationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassificationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareClassificationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassificationTemplateSpec) DeepCopyInto(out *HardwareClassificationTemplateSpec) {
+	*out = *in
+	in.HardwareCharacteristics.DeepCopyInto(&out.HardwareCharacteristics)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationTemplateSpec.
+func (in *HardwareClassificationTemplateSpec) DeepCopy() *HardwareClassificationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassificationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareGroup) DeepCopyInto(out *HardwareGroup) {
+	*out = *in
+	in.Hardware.DeepCopyInto(&out.Hardware)
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareGroup.
+func (in *HardwareGroup) DeepCopy() *HardwareGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareInventory) DeepCopyInto(out *HardwareInventory) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareInventory.
+func (in *HardwareInventory) DeepCopy() *HardwareInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareInventory) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareInventoryList) DeepCopyInto(out *HardwareInventoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HardwareInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareInventoryList.
+func (in *HardwareInventoryList) DeepCopy() *HardwareInventoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareInventoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareInventoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareInventorySpec) DeepCopyInto(out *HardwareInventorySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareInventorySpec.
+func (in *HardwareInventorySpec) DeepCopy() *HardwareInventorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareInventorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareInventoryStatus) DeepCopyInto(out *HardwareInventoryStatus) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]HardwareGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Coverage != nil {
+		in, out := &in.Coverage, &out.Coverage
+		*out = new(ProfileCoverage)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareInventoryStatus.
+func (in *HardwareInventoryStatus) DeepCopy() *HardwareInventoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareInventoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareSignature) DeepCopyInto(out *HardwareSignature) {
+	*out = *in
+	out.CPU = in.CPU
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]DiskSignature, len(*in))
+		copy(*out, *in)
+	}
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]NICSignature, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareSignature.
+func (in *HardwareSignature) DeepCopy() *HardwareSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostOverlap) DeepCopyInto(out *HostOverlap) {
+	*out = *in
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostOverlap.
+func (in *HostOverlap) DeepCopy() *HostOverlap {
+	if in == nil {
+		return nil
+	}
+	out := new(HostOverlap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostScore) DeepCopyInto(out *HostScore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostScore.
+func (in *HostScore) DeepCopy() *HostScore {
+	if in == nil {
+		return nil
+	}
+	out := new(HostScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NICSignature) DeepCopyInto(out *NICSignature) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICSignature.
+func (in *NICSignature) DeepCopy() *NICSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(NICSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Nic) DeepCopyInto(out *Nic) {
 	*out = *in
@@ -276,9 +747,66 @@ func (in *NicSelector) DeepCopy() *NicSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileCoverage) DeepCopyInto(out *ProfileCoverage) {
+	*out = *in
+	if in.UnclassifiedHosts != nil {
+		in, out := &in.UnclassifiedHosts, &out.UnclassifiedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OverlappingHosts != nil {
+		in, out := &in.OverlappingHosts, &out.OverlappingHosts
+		*out = make([]HostOverlap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubsumedProfiles != nil {
+		in, out := &in.SubsumedProfiles, &out.SubsumedProfiles
+		*out = make([]ProfileSubsumption, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileCoverage.
+func (in *ProfileCoverage) DeepCopy() *ProfileCoverage {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileCoverage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileSubsumption) DeepCopyInto(out *ProfileSubsumption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileSubsumption.
+func (in *ProfileSubsumption) DeepCopy() *ProfileSubsumption {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileSubsumption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ram) DeepCopyInto(out *Ram) {
 	*out = *in
+	if in.MinimumSize != nil {
+		in, out := &in.MinimumSize, &out.MinimumSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaximumSize != nil {
+		in, out := &in.MaximumSize, &out.MaximumSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ram.
@@ -291,6 +819,37 @@ func (in *Ram) DeepCopy() *Ram {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scoring) DeepCopyInto(out *Scoring) {
+	*out = *in
+	out.Weights = in.Weights
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scoring.
+func (in *Scoring) DeepCopy() *Scoring {
+	if in == nil {
+		return nil
+	}
+	out := new(Scoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScoringWeights) DeepCopyInto(out *ScoringWeights) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScoringWeights.
+func (in *ScoringWeights) DeepCopy() *ScoringWeights {
+	if in == nil {
+		return nil
+	}
+	out := new(ScoringWeights)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SystemVendor) DeepCopyInto(out *SystemVendor) {
 	*out = *in
@@ -305,3 +864,18 @@ func (in *SystemVendor) DeepCopy() *SystemVendor {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateReference) DeepCopyInto(out *TemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateReference.
+func (in *TemplateReference) DeepCopy() *TemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,482 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// ConversionDataAnnotation stores on the v1alpha2 object the details of
+// the v1alpha1 object v1alpha2 cannot represent, so they survive a round
+// trip through v1alpha2. Every v1alpha2 field has a v1alpha1 equivalent.
+const ConversionDataAnnotation = "hardwareclassification.metal3.io/conversion-data"
+
+// The units of the deprecated v1alpha1 sizes, RAM sizes are named GB
+// but compared with the host sizes in GiB.
+const (
+	gigabyte = 1000 * 1000 * 1000
+	gibibyte = 1 << 30
+)
+
+// conversionData is the content of the ConversionDataAnnotation, the
+// v1alpha1 disk and RAM rules using the deprecated sizes in GB.
+type conversionData struct {
+	Disk *v1alpha1.Disk `json:"disk,omitempty"`
+	Ram  *v1alpha1.Ram  `json:"ram,omitempty"`
+}
+
+// ConvertTo converts this HardwareClassification to the hub version.
+func (src *HardwareClassification) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.HardwareClassification)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecTo(&src.Spec, &dst.Spec)
+	convertStatusTo(&src.Status, &dst.Status)
+
+	data, ok := dst.Annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(dst.Annotations, ConversionDataAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	restored := conversionData{}
+	if err := json.Unmarshal([]byte(data), &restored); err != nil {
+		return err
+	}
+
+	// The stored rules are only used while they still describe the
+	// v1alpha2 object, as it may have been updated since.
+	stored := HardwareCharacteristics{}
+	convertCharacteristicsFrom(&v1alpha1.HardwareCharacteristics{
+		Disk: restored.Disk,
+		Ram:  restored.Ram,
+	}, &stored)
+	characteristics := &dst.Spec.HardwareCharacteristics
+	if restored.Disk != nil && equality.Semantic.DeepEqual(stored.Disk, src.Spec.HardwareCharacteristics.Disk) {
+		characteristics.Disk = restored.Disk
+	}
+	if restored.Ram != nil && equality.Semantic.DeepEqual(stored.RAM, src.Spec.HardwareCharacteristics.RAM) {
+		characteristics.Ram = restored.Ram
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version to this version.
+func (dst *HardwareClassification) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.HardwareClassification)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecFrom(&src.Spec, &dst.Spec)
+	convertStatusFrom(&src.Status, &dst.Status)
+
+	// Objects written before only stored the v1alpha2 details, they are
+	// replaced.
+	delete(dst.Annotations, ConversionDataAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	data := conversionData{}
+	if disk := src.Spec.HardwareCharacteristics.Disk; disk != nil &&
+		(disk.MinimumIndividualSizeGB != 0 || disk.MaximumIndividualSizeGB != 0) {
+		data.Disk = disk.DeepCopy()
+	}
+	if ram := src.Spec.HardwareCharacteristics.Ram; ram != nil &&
+		(ram.MinimumSizeGB != 0 || ram.MaximumSizeGB != 0) {
+		data.Ram = ram.DeepCopy()
+	}
+	if data.Disk == nil && data.Ram == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[ConversionDataAnnotation] = string(encoded)
+	return nil
+}
+
+func convertSpecTo(src *HardwareClassificationSpec, dst *v1alpha1.HardwareClassificationSpec) {
+	dst.HardwareCharacteristics = v1alpha1.HardwareCharacteristics{}
+	convertCharacteristicsTo(&src.HardwareCharacteristics, &dst.HardwareCharacteristics)
+
+	dst.TemplateRef = nil
+	if src.TemplateRef != nil {
+		dst.TemplateRef = &v1alpha1.TemplateReference{
+			Name:      src.TemplateRef.Name,
+			Namespace: src.TemplateRef.Namespace,
+		}
+	}
+
+	dst.Scoring = nil
+	if src.Scoring != nil {
+		dst.Scoring = &v1alpha1.Scoring{
+			MinimumScore: int(src.Scoring.MinimumScore),
+			Weights: v1alpha1.ScoringWeights{
+				Cpu:          int(src.Scoring.Weights.CPU),
+				Disk:         int(src.Scoring.Weights.Disk),
+				Nic:          int(src.Scoring.Weights.NIC),
+				Ram:          int(src.Scoring.Weights.RAM),
+				SystemVendor: int(src.Scoring.Weights.SystemVendor),
+				Firmware:     int(src.Scoring.Weights.Firmware),
+			},
+			AnnotateHosts: src.Scoring.AnnotateHosts,
+		}
+	}
+
+	dst.MaxHosts = int(src.MaxHosts)
+	dst.MinHosts = int(src.MinHosts)
+	dst.SelectionPolicy = v1alpha1.SelectionPolicy(src.SelectionPolicy)
+	dst.UnlabelPolicy = v1alpha1.UnlabelPolicy(src.UnlabelPolicy)
+}
+
+func convertSpecFrom(src *v1alpha1.HardwareClassificationSpec, dst *HardwareClassificationSpec) {
+	dst.HardwareCharacteristics = HardwareCharacteristics{}
+	convertCharacteristicsFrom(&src.HardwareCharacteristics, &dst.HardwareCharacteristics)
+
+	dst.TemplateRef = nil
+	if src.TemplateRef != nil {
+		dst.TemplateRef = &TemplateReference{
+			Name:      src.TemplateRef.Name,
+			Namespace: src.TemplateRef.Namespace,
+		}
+	}
+
+	dst.Scoring = nil
+	if src.Scoring != nil {
+		dst.Scoring = &Scoring{
+			MinimumScore: int32(src.Scoring.MinimumScore),
+			Weights: ScoringWeights{
+				CPU:          int32(src.Scoring.Weights.Cpu),
+				Disk:         int32(src.Scoring.Weights.Disk),
+				NIC:          int32(src.Scoring.Weights.Nic),
+				RAM:          int32(src.Scoring.Weights.Ram),
+				SystemVendor: int32(src.Scoring.Weights.SystemVendor),
+				Firmware:     int32(src.Scoring.Weights.Firmware),
+			},
+			AnnotateHosts: src.Scoring.AnnotateHosts,
+		}
+	}
+
+	dst.MaxHosts = int32(src.MaxHosts)
+	dst.MinHosts = int32(src.MinHosts)
+	dst.SelectionPolicy = SelectionPolicy(src.SelectionPolicy)
+	dst.UnlabelPolicy = UnlabelPolicy(src.UnlabelPolicy)
+}
+
+func convertCharacteristicsTo(src *HardwareCharacteristics, dst *v1alpha1.HardwareCharacteristics) {
+	if src.CPU != nil {
+		dst.Cpu = &v1alpha1.Cpu{
+			Architecture:    src.CPU.Architecture,
+			MinimumCount:    int(src.CPU.MinimumCount),
+			MaximumCount:    int(src.CPU.MaximumCount),
+			MinimumSpeedMHz: src.CPU.MinimumSpeedMHz,
+			MaximumSpeedMHz: src.CPU.MaximumSpeedMHz,
+		}
+	}
+	if src.Disk != nil {
+		dst.Disk = &v1alpha1.Disk{
+			MinimumCount:          int(src.Disk.MinimumCount),
+			MaximumCount:          int(src.Disk.MaximumCount),
+			MinimumIndividualSize: copyQuantity(src.Disk.MinimumIndividualSize),
+			MaximumIndividualSize: copyQuantity(src.Disk.MaximumIndividualSize),
+			SizeTolerancePercent:  int(src.Disk.SizeTolerancePercent),
+		}
+		if src.Disk.DiskSelector != nil {
+			dst.Disk.DiskSelector = []v1alpha1.DiskSelector{}
+		}
+		for _, selector := range src.Disk.DiskSelector {
+			dst.Disk.DiskSelector = append(dst.Disk.DiskSelector, v1alpha1.DiskSelector{
+				HCTL:       selector.HCTL,
+				Rotational: copyBool(selector.Rotational),
+				Type:       v1alpha1.DiskType(selector.Type),
+			})
+		}
+	}
+	if src.NIC != nil {
+		dst.Nic = &v1alpha1.Nic{
+			NicSelector: v1alpha1.NicSelector{
+				Vendor: copyStrings(src.NIC.NICSelector.Vendor),
+			},
+			MinimumCount: int(src.NIC.MinimumCount),
+			MaximumCount: int(src.NIC.MaximumCount),
+		}
+	}
+	if src.RAM != nil {
+		dst.Ram = &v1alpha1.Ram{
+			MinimumSize:          copyQuantity(src.RAM.MinimumSize),
+			MaximumSize:          copyQuantity(src.RAM.MaximumSize),
+			SizeTolerancePercent: int(src.RAM.SizeTolerancePercent),
+		}
+	}
+	if src.SystemVendor != nil {
+		dst.SystemVendor = &v1alpha1.SystemVendor{
+			Manufacturer: src.SystemVendor.Manufacturer,
+			ProductName:  src.SystemVendor.ProductName,
+		}
+	}
+	if src.Firmware != nil {
+		dst.Firmware = &v1alpha1.Firmware{
+			BIOS: v1alpha1.BIOS{
+				Vendor:       src.Firmware.BIOS.Vendor,
+				MinorVersion: src.Firmware.BIOS.MinimumVersion,
+				MajorVersion: src.Firmware.BIOS.MaximumVersion,
+			},
+		}
+	}
+}
+
+func convertCharacteristicsFrom(src *v1alpha1.HardwareCharacteristics, dst *HardwareCharacteristics) {
+	if src.Cpu != nil {
+		dst.CPU = &CPU{
+			Architecture:    src.Cpu.Architecture,
+			MinimumCount:    int32(src.Cpu.MinimumCount),
+			MaximumCount:    int32(src.Cpu.MaximumCount),
+			MinimumSpeedMHz: src.Cpu.MinimumSpeedMHz,
+			MaximumSpeedMHz: src.Cpu.MaximumSpeedMHz,
+		}
+	}
+	if src.Disk != nil {
+		dst.Disk = &Disk{
+			MinimumCount:          int32(src.Disk.MinimumCount),
+			MaximumCount:          int32(src.Disk.MaximumCount),
+			MinimumIndividualSize: legacySize(src.Disk.MinimumIndividualSize, src.Disk.MinimumIndividualSizeGB, gigabyte, resource.DecimalSI),
+			MaximumIndividualSize: legacySize(src.Disk.MaximumIndividualSize, src.Disk.MaximumIndividualSizeGB, gigabyte, resource.DecimalSI),
+			SizeTolerancePercent:  int32(src.Disk.SizeTolerancePercent),
+		}
+		if src.Disk.DiskSelector != nil {
+			dst.Disk.DiskSelector = []DiskSelector{}
+		}
+		for _, selector := range src.Disk.DiskSelector {
+			dst.Disk.DiskSelector = append(dst.Disk.DiskSelector, DiskSelector{
+				HCTL:       selector.HCTL,
+				Rotational: copyBool(selector.Rotational),
+				Type:       DiskType(selector.Type),
+			})
+		}
+	}
+	if src.Nic != nil {
+		dst.NIC = &NIC{
+			NICSelector: NICSelector{
+				Vendor: copyStrings(src.Nic.NicSelector.Vendor),
+			},
+			MinimumCount: int32(src.Nic.MinimumCount),
+			MaximumCount: int32(src.Nic.MaximumCount),
+		}
+	}
+	if src.Ram != nil {
+		dst.RAM = &RAM{
+			MinimumSize:          legacySize(src.Ram.MinimumSize, int64(src.Ram.MinimumSizeGB), gibibyte, resource.BinarySI),
+			MaximumSize:          legacySize(src.Ram.MaximumSize, int64(src.Ram.MaximumSizeGB), gibibyte, resource.BinarySI),
+			SizeTolerancePercent: int32(src.Ram.SizeTolerancePercent),
+		}
+	}
+	if src.SystemVendor != nil {
+		dst.SystemVendor = &SystemVendor{
+			Manufacturer: src.SystemVendor.Manufacturer,
+			ProductName:  src.SystemVendor.ProductName,
+		}
+	}
+	if src.Firmware != nil {
+		dst.Firmware = &Firmware{
+			BIOS: BIOS{
+				Vendor:         src.Firmware.BIOS.Vendor,
+				MinimumVersion: src.Firmware.BIOS.MinorVersion,
+				MaximumVersion: src.Firmware.BIOS.MajorVersion,
+			},
+		}
+	}
+}
+
+// v1alpha1ErrorHosts maps the keys of the ErrorHosts map to the
+// v1alpha1 counters.
+func v1alpha1ErrorHosts(status *v1alpha1.HardwareClassificationStatus) map[string]*int {
+	return map[string]*int{
+		RegistrationError:            (*int)(&status.RegistrationErrorHosts),
+		InspectionError:              (*int)(&status.IntrospectionErrorHosts),
+		ProvisioningError:            (*int)(&status.ProvisioningErrorHosts),
+		PowerManagementError:         (*int)(&status.PowerMgmtErrorHosts),
+		ProvisionedRegistrationError: (*int)(&status.ProvisionedRegistrationErrorHosts),
+		PreparationError:             (*int)(&status.PreparationErrorHosts),
+		DetachError:                  (*int)(&status.DetachErrorHosts),
+	}
+}
+
+func convertStatusTo(src *HardwareClassificationStatus, dst *v1alpha1.HardwareClassificationStatus) {
+	*dst = v1alpha1.HardwareClassificationStatus{
+		ErrorType:          v1alpha1.ErrorType(src.ErrorType),
+		ProfileMatchStatus: v1alpha1.ProfileMatchStatus(src.ProfileMatchStatus),
+		MatchedCount:       v1alpha1.MatchedCount(src.MatchedCount),
+		UnmatchedCount:     v1alpha1.UnmatchedCount(src.UnmatchedCount),
+		ErrorHosts:         v1alpha1.ErrorHosts(src.ErrorHostCount),
+		ErrorMessage:       src.ErrorMessage,
+		EligibleCount:      int(src.EligibleCount),
+		LabelledCount:      int(src.LabelledCount),
+		SelectedHosts:      copyStrings(src.SelectedHosts),
+		StaleHosts:         copyStrings(src.StaleHosts),
+		ConsumedCount:      int(src.ConsumedCount),
+		AvailableCount:     int(src.AvailableCount),
+	}
+	if src.MatchedHostsByState != nil {
+		dst.MatchedHostsByState = map[string]int{}
+	}
+	for state, count := range src.MatchedHostsByState {
+		dst.MatchedHostsByState[state] = int(count)
+	}
+	for errorType, counter := range v1alpha1ErrorHosts(dst) {
+		*counter = int(src.ErrorHosts[errorType])
+	}
+	if src.ErrorHosts != nil {
+		dst.ErrorHostsByType = map[string]int{}
+	}
+	for errorType, count := range src.ErrorHosts {
+		dst.ErrorHostsByType[errorType] = int(count)
+	}
+	if src.FailingHosts != nil {
+		dst.FailingHosts = []v1alpha1.FailingHost{}
+	}
+	for _, host := range src.FailingHosts {
+		dst.FailingHosts = append(dst.FailingHosts, v1alpha1.FailingHost(host))
+	}
+	if src.HostScores != nil {
+		dst.HostScores = []v1alpha1.HostScore{}
+	}
+	for _, score := range src.HostScores {
+		dst.HostScores = append(dst.HostScores, v1alpha1.HostScore{
+			Name:  score.Name,
+			Score: int(score.Score),
+		})
+	}
+	if src.EffectiveHardwareCharacteristics != nil {
+		dst.EffectiveHardwareCharacteristics = &v1alpha1.HardwareCharacteristics{}
+		convertCharacteristicsTo(src.EffectiveHardwareCharacteristics, dst.EffectiveHardwareCharacteristics)
+	}
+	for _, condition := range src.Conditions {
+		dst.Conditions = append(dst.Conditions, *condition.DeepCopy())
+	}
+}
+
+func convertStatusFrom(src *v1alpha1.HardwareClassificationStatus, dst *HardwareClassificationStatus) {
+	*dst = HardwareClassificationStatus{
+		ErrorType:          ErrorType(src.ErrorType),
+		ProfileMatchStatus: ProfileMatchStatus(src.ProfileMatchStatus),
+		MatchedCount:       int32(src.MatchedCount),
+		UnmatchedCount:     int32(src.UnmatchedCount),
+		ErrorHostCount:     int32(src.ErrorHosts),
+		ErrorMessage:       src.ErrorMessage,
+		EligibleCount:      int32(src.EligibleCount),
+		LabelledCount:      int32(src.LabelledCount),
+		SelectedHosts:      copyStrings(src.SelectedHosts),
+		StaleHosts:         copyStrings(src.StaleHosts),
+		ConsumedCount:      int32(src.ConsumedCount),
+		AvailableCount:     int32(src.AvailableCount),
+	}
+	if src.MatchedHostsByState != nil {
+		dst.MatchedHostsByState = map[string]int32{}
+	}
+	for state, count := range src.MatchedHostsByState {
+		dst.MatchedHostsByState[state] = int32(count)
+	}
+	if src.ErrorHostsByType != nil {
+		dst.ErrorHosts = map[string]int32{}
+		for errorType, count := range src.ErrorHostsByType {
+			dst.ErrorHosts[errorType] = int32(count)
+		}
+	} else {
+		// Statuses written before the breakdown by error type only
+		// have the counters, the error types with hosts are listed.
+		for errorType, counter := range v1alpha1ErrorHosts(src) {
+			if *counter == 0 {
+				continue
+			}
+			if dst.ErrorHosts == nil {
+				dst.ErrorHosts = map[string]int32{}
+			}
+			dst.ErrorHosts[errorType] = int32(*counter)
+		}
+	}
+	if src.FailingHosts != nil {
+		dst.FailingHosts = []FailingHost{}
+	}
+	for _, host := range src.FailingHosts {
+		dst.FailingHosts = append(dst.FailingHosts, FailingHost(host))
+	}
+	if src.HostScores != nil {
+		dst.HostScores = []HostScore{}
+	}
+	for _, score := range src.HostScores {
+		dst.HostScores = append(dst.HostScores, HostScore{
+			Name:  score.Name,
+			Score: int32(score.Score),
+		})
+	}
+	if src.EffectiveHardwareCharacteristics != nil {
+		dst.EffectiveHardwareCharacteristics = &HardwareCharacteristics{}
+		convertCharacteristicsFrom(src.EffectiveHardwareCharacteristics, dst.EffectiveHardwareCharacteristics)
+	}
+	for _, condition := range src.Conditions {
+		dst.Conditions = append(dst.Conditions, *condition.DeepCopy())
+	}
+}
+
+// legacySize returns the size, falling back to the deprecated v1alpha1
+// size given in units. Objects written through v1alpha2 therefore only
+// keep the quantity.
+func legacySize(size *resource.Quantity, legacy, unit int64, format resource.Format) *resource.Quantity {
+	if size != nil {
+		return copyQuantity(size)
+	}
+	if legacy == 0 {
+		return nil
+	}
+	return resource.NewQuantity(legacy*unit, format)
+}
+
+func copyQuantity(q *resource.Quantity) *resource.Quantity {
+	if q == nil {
+		return nil
+	}
+	out := q.DeepCopy()
+	return &out
+}
+
+func copyBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	out := *b
+	return &out
+}
+
+func copyStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	return append([]string{}, in...)
+}
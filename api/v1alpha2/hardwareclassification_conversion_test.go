@@ -0,0 +1,222 @@
+package v1alpha2
+
+import (
+	"math/rand"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+const fuzzIterations = 1000
+
+func newFuzzer(t *testing.T, funcs ...interface{}) *fuzz.Fuzzer {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, AddToScheme(scheme))
+
+	common := []interface{}{
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1<<40), resource.BinarySI)
+		},
+		func(condition *metav1.Condition, c fuzz.Continue) {
+			c.FuzzNoCustom(condition)
+			// Times are serialised with a precision of one second.
+			condition.LastTransitionTime = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+	}
+
+	return fuzzer.FuzzerFor(
+		fuzzer.MergeFuzzerFuncs(
+			metafuzzer.Funcs,
+			func(serializer.CodecFactory) []interface{} { return append(common, funcs...) },
+		),
+		rand.NewSource(rand.Int63()),
+		serializer.NewCodecFactory(scheme),
+	)
+}
+
+// hubFuzzerFuncs keep the v1alpha1 numbers in the ranges v1alpha2 can
+// represent and leave the deprecated sizes of the status, which is not
+// kept in the conversion data, unset.
+var hubFuzzerFuncs = []interface{}{
+	// The counters are derived from the breakdown by error type.
+	func(status *v1alpha1.HardwareClassificationStatus, c fuzz.Continue) {
+		c.FuzzNoCustom(status)
+		for errorType, counter := range v1alpha1ErrorHosts(status) {
+			*counter = status.ErrorHostsByType[errorType]
+		}
+		if characteristics := status.EffectiveHardwareCharacteristics; characteristics != nil {
+			if characteristics.Disk != nil {
+				characteristics.Disk.MinimumIndividualSizeGB = 0
+				characteristics.Disk.MaximumIndividualSizeGB = 0
+			}
+			if characteristics.Ram != nil {
+				characteristics.Ram.MinimumSizeGB = 0
+				characteristics.Ram.MaximumSizeGB = 0
+			}
+		}
+	},
+	func(i *int, c fuzz.Continue) { *i = int(c.Int31()) },
+	func(i *int64, c fuzz.Continue) { *i = int64(c.Int31()) },
+	func(i *v1alpha1.MatchedCount, c fuzz.Continue) { *i = v1alpha1.MatchedCount(c.Int31()) },
+	func(i *v1alpha1.UnmatchedCount, c fuzz.Continue) { *i = v1alpha1.UnmatchedCount(c.Int31()) },
+	func(i *v1alpha1.ErrorHosts, c fuzz.Continue) { *i = v1alpha1.ErrorHosts(c.Int31()) },
+	func(i *v1alpha1.RegistrationErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.RegistrationErrorHosts(c.Int31())
+	},
+	func(i *v1alpha1.IntrospectionErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.IntrospectionErrorHosts(c.Int31())
+	},
+	func(i *v1alpha1.ProvisioningErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.ProvisioningErrorHosts(c.Int31())
+	},
+	func(i *v1alpha1.PowerMgmtErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.PowerMgmtErrorHosts(c.Int31())
+	},
+	func(i *v1alpha1.ProvisionedRegistrationErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.ProvisionedRegistrationErrorHosts(c.Int31())
+	},
+	func(i *v1alpha1.PreparationErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.PreparationErrorHosts(c.Int31())
+	},
+	func(i *v1alpha1.DetachErrorHosts, c fuzz.Continue) {
+		*i = v1alpha1.DetachErrorHosts(c.Int31())
+	},
+}
+
+func TestHubSpokeHub(t *testing.T) {
+	f := newFuzzer(t, hubFuzzerFuncs...)
+
+	for i := 0; i < fuzzIterations; i++ {
+		hub := &v1alpha1.HardwareClassification{}
+		f.Fuzz(hub)
+
+		spoke := &HardwareClassification{}
+		assert.NoError(t, spoke.ConvertFrom(hub.DeepCopy()))
+		result := &v1alpha1.HardwareClassification{}
+		assert.NoError(t, spoke.ConvertTo(result))
+
+		delete(result.Annotations, ConversionDataAnnotation)
+		if !equality.Semantic.DeepEqual(hub, result) {
+			t.Fatalf("round trip changed the object:\n%#v\n%#v", hub, result)
+		}
+	}
+}
+
+func TestSpokeHubSpoke(t *testing.T) {
+	f := newFuzzer(t)
+
+	for i := 0; i < fuzzIterations; i++ {
+		spoke := &HardwareClassification{}
+		f.Fuzz(spoke)
+		delete(spoke.Annotations, ConversionDataAnnotation)
+
+		hub := &v1alpha1.HardwareClassification{}
+		assert.NoError(t, spoke.DeepCopy().ConvertTo(hub))
+		result := &HardwareClassification{}
+		assert.NoError(t, result.ConvertFrom(hub))
+
+		if !equality.Semantic.DeepEqual(spoke, result) {
+			t.Fatalf("round trip changed the object:\n%#v\n%#v", spoke, result)
+		}
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	rotational := true
+	spoke := &HardwareClassification{
+		Spec: HardwareClassificationSpec{
+			HardwareCharacteristics: HardwareCharacteristics{
+				RAM: &RAM{MinimumSize: resource.NewQuantity(1536<<20, resource.BinarySI)},
+				Disk: &Disk{
+					DiskSelector: []DiskSelector{{HCTL: "0:0:0:0"}, {Rotational: &rotational}},
+				},
+				Firmware: &Firmware{
+					BIOS: BIOS{MinimumVersion: "1.0", MaximumVersion: "2.0"},
+				},
+			},
+		},
+		Status: HardwareClassificationStatus{
+			ErrorHostCount: 2,
+			ErrorHosts:     map[string]int32{InspectionError: 1, "unknown error": 1},
+		},
+	}
+
+	hub := &v1alpha1.HardwareClassification{}
+	assert.NoError(t, spoke.ConvertTo(hub))
+	// v1alpha1 represents every v1alpha2 field, nothing is stored.
+	assert.Nil(t, hub.Annotations)
+	assert.Equal(t, "1536Mi", hub.Spec.HardwareCharacteristics.Ram.MinimumSize.String())
+	assert.Equal(t, "1.0", hub.Spec.HardwareCharacteristics.Firmware.BIOS.MinorVersion)
+	assert.Equal(t, "2.0", hub.Spec.HardwareCharacteristics.Firmware.BIOS.MajorVersion)
+	assert.Equal(t, []v1alpha1.DiskSelector{{HCTL: "0:0:0:0"}, {Rotational: &rotational}},
+		hub.Spec.HardwareCharacteristics.Disk.DiskSelector)
+	assert.Equal(t, v1alpha1.IntrospectionErrorHosts(1), hub.Status.IntrospectionErrorHosts)
+}
+
+func TestConvertFromLegacySizes(t *testing.T) {
+	hub := &v1alpha1.HardwareClassification{
+		Spec: v1alpha1.HardwareClassificationSpec{
+			HardwareCharacteristics: v1alpha1.HardwareCharacteristics{
+				Disk: &v1alpha1.Disk{
+					MinimumIndividualSizeGB: 100,
+					MaximumIndividualSizeGB: 200,
+					MaximumIndividualSize:   resource.NewQuantity(2e12, resource.DecimalSI),
+				},
+				Ram: &v1alpha1.Ram{MinimumSizeGB: 16, MaximumSizeGB: 32},
+			},
+		},
+	}
+
+	spoke := &HardwareClassification{}
+	assert.NoError(t, spoke.ConvertFrom(hub.DeepCopy()))
+	disk := spoke.Spec.HardwareCharacteristics.Disk
+	assert.Equal(t, "100G", disk.MinimumIndividualSize.String())
+	assert.Equal(t, "2T", disk.MaximumIndividualSize.String())
+	ram := spoke.Spec.HardwareCharacteristics.RAM
+	assert.Equal(t, "16Gi", ram.MinimumSize.String())
+	assert.Equal(t, "32Gi", ram.MaximumSize.String())
+	assert.Contains(t, spoke.Annotations, ConversionDataAnnotation)
+
+	// The deprecated sizes are restored while the v1alpha2 sizes are
+	// unchanged.
+	result := &v1alpha1.HardwareClassification{}
+	assert.NoError(t, spoke.DeepCopy().ConvertTo(result))
+	assert.Nil(t, result.Annotations)
+	assert.True(t, equality.Semantic.DeepEqual(hub.Spec, result.Spec))
+
+	spoke.Spec.HardwareCharacteristics.RAM.MinimumSize = resource.NewQuantity(24<<30, resource.BinarySI)
+	result = &v1alpha1.HardwareClassification{}
+	assert.NoError(t, spoke.ConvertTo(result))
+	assert.True(t, equality.Semantic.DeepEqual(hub.Spec.HardwareCharacteristics.Disk,
+		result.Spec.HardwareCharacteristics.Disk))
+	assert.True(t, equality.Semantic.DeepEqual(&v1alpha1.Ram{
+		MinimumSize: resource.NewQuantity(24<<30, resource.BinarySI),
+		MaximumSize: resource.NewQuantity(32<<30, resource.BinarySI),
+	}, result.Spec.HardwareCharacteristics.Ram))
+}
+
+func TestConvertFromLegacyErrorCounters(t *testing.T) {
+	hub := &v1alpha1.HardwareClassification{
+		Status: v1alpha1.HardwareClassificationStatus{
+			ErrorHosts:              3,
+			RegistrationErrorHosts:  1,
+			IntrospectionErrorHosts: 2,
+		},
+	}
+
+	spoke := &HardwareClassification{}
+	assert.NoError(t, spoke.ConvertFrom(hub))
+	assert.Equal(t, int32(3), spoke.Status.ErrorHostCount)
+	assert.Equal(t, map[string]int32{RegistrationError: 1, InspectionError: 2}, spoke.Status.ErrorHosts)
+}
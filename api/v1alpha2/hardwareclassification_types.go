@@ -0,0 +1,415 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HardwareClassificationSpec defines the desired state of HardwareClassification
+type HardwareClassificationSpec struct {
+	// HardwareCharacteristics defines expected hardware configurations for CPU, Disk, NIC, RAM, SystemVendor and Firmware.
+	HardwareCharacteristics HardwareCharacteristics `json:"hardwareCharacteristics,omitempty"`
+
+	// +optional
+	// TemplateRef names a HardwareClassificationTemplate providing
+	// the characteristics of the profile. The characteristics set in
+	// HardwareCharacteristics override the ones of the template.
+	TemplateRef *TemplateReference `json:"templateRef,omitempty"`
+
+	// Scoring enables matching hosts on a weighted fit score instead
+	// of requiring every characteristic to match.
+	// +optional
+	Scoring *Scoring `json:"scoring,omitempty"`
+
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// MaxHosts is the maximum number of matching hosts labelled for
+	// the profile. When more hosts match, the SelectionPolicy decides
+	// which of them are labelled.
+	MaxHosts int32 `json:"maxHosts,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// MinHosts is the number of matching hosts the profile is
+	// expected to have. It is only reported through the status
+	// conditions and does not change which hosts are labelled.
+	MinHosts int32 `json:"minHosts,omitempty"`
+	// +optional
+	// SelectionPolicy decides which matching hosts are labelled when
+	// more hosts than MaxHosts match. Defaults to Oldest.
+	SelectionPolicy SelectionPolicy `json:"selectionPolicy,omitempty"`
+	// +optional
+	// UnlabelPolicy decides whether the label is removed from hosts
+	// which no longer match the profile. Defaults to Always.
+	UnlabelPolicy UnlabelPolicy `json:"unlabelPolicy,omitempty"`
+}
+
+// TemplateReference identifies a HardwareClassificationTemplate.
+type TemplateReference struct {
+	// Name of the template.
+	Name string `json:"name"`
+	// +optional
+	// Namespace of the template. Defaults to the namespace of the
+	// profile.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SelectionPolicy is the order in which matching hosts are selected
+// for labelling when the profile limits the number of hosts.
+// +kubebuilder:validation:Enum=Oldest;Name;BestScore
+type SelectionPolicy string
+
+const (
+	// SelectionPolicyOldest selects the hosts created first.
+	SelectionPolicyOldest SelectionPolicy = "Oldest"
+	// SelectionPolicyName selects the hosts in name order.
+	SelectionPolicyName SelectionPolicy = "Name"
+	// SelectionPolicyBestScore selects the hosts with the highest fit
+	// score.
+	SelectionPolicyBestScore SelectionPolicy = "BestScore"
+)
+
+// UnlabelPolicy controls when the label is removed from a host which
+// no longer matches the profile.
+// +kubebuilder:validation:Enum=Always;OnlyIfNotProvisioned;Never
+type UnlabelPolicy string
+
+const (
+	// UnlabelPolicyAlways removes the label as soon as the host no
+	// longer matches.
+	UnlabelPolicyAlways UnlabelPolicy = "Always"
+	// UnlabelPolicyOnlyIfNotProvisioned keeps the label on hosts
+	// which are provisioned or consumed.
+	UnlabelPolicyOnlyIfNotProvisioned UnlabelPolicy = "OnlyIfNotProvisioned"
+	// UnlabelPolicyNever keeps the label on all hosts until the
+	// profile is deleted.
+	UnlabelPolicyNever UnlabelPolicy = "Never"
+)
+
+// Scoring configures score based classification
+type Scoring struct {
+	// MinimumScore is the lowest fit score, in percent, a host needs
+	// to be considered a match for the profile.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinimumScore int32 `json:"minimumScore"`
+	// +optional
+	// Weights of the characteristics contributing to the score.
+	Weights ScoringWeights `json:"weights,omitempty"`
+	// +optional
+	// AnnotateHosts records the score of the profile on each host
+	// with an annotation.
+	AnnotateHosts bool `json:"annotateHosts,omitempty"`
+}
+
+// ScoringWeights are the relative weights of the characteristics. A
+// characteristic without a weight counts once.
+type ScoringWeights struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CPU int32 `json:"cpu,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Disk int32 `json:"disk,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	NIC int32 `json:"nic,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RAM int32 `json:"ram,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	SystemVendor int32 `json:"systemVendor,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Firmware int32 `json:"firmware,omitempty"`
+}
+
+// HardwareCharacteristics details to match with the host
+type HardwareCharacteristics struct {
+	// +optional
+	CPU *CPU `json:"cpu,omitempty"`
+	// +optional
+	Disk *Disk `json:"disk,omitempty"`
+	// +optional
+	NIC *NIC `json:"nic,omitempty"`
+	// +optional
+	RAM *RAM `json:"ram,omitempty"`
+	// +optional
+	SystemVendor *SystemVendor `json:"systemVendor,omitempty"`
+	// +optional
+	Firmware *Firmware `json:"firmware,omitempty"`
+}
+
+// SystemVendor contains the expected system vendor details
+type SystemVendor struct {
+	// +optional
+	Manufacturer string `json:"manufacturer,omitempty"`
+	// +optional
+	ProductName string `json:"productName,omitempty"`
+}
+
+// Firmware contains the expected firmware details
+type Firmware struct {
+	// +optional
+	BIOS BIOS `json:"bios,omitempty"`
+}
+
+// BIOS contains the expected BIOS details
+type BIOS struct {
+	// +optional
+	Vendor string `json:"vendor,omitempty"`
+	// +optional
+	// MinimumVersion is the oldest BIOS version accepted
+	MinimumVersion string `json:"minimumVersion,omitempty"`
+	// +optional
+	// MaximumVersion is the newest BIOS version accepted
+	MaximumVersion string `json:"maximumVersion,omitempty"`
+}
+
+// CPU contains the expected CPU details
+type CPU struct {
+	// +optional
+	// +kubebuilder:validation:Enum=x86;x86_64;IAS;AMD64
+	Architecture string `json:"architecture,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinimumCount int32 `json:"minimumCount,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaximumCount int32 `json:"maximumCount,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1000
+	MinimumSpeedMHz int32 `json:"minimumSpeedMHz,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1000
+	MaximumSpeedMHz int32 `json:"maximumSpeedMHz,omitempty"`
+}
+
+// DiskType is the kind of a disk, derived from the rotational flag and
+// the name of the disk reported by the host.
+// +kubebuilder:validation:Enum=HDD;SSD;NVMe
+type DiskType string
+
+const (
+	// DiskTypeHDD is a rotational disk.
+	DiskTypeHDD DiskType = "HDD"
+	// DiskTypeSSD is a non-rotational disk which is not NVMe.
+	DiskTypeSSD DiskType = "SSD"
+	// DiskTypeNVMe is a NVMe disk.
+	DiskTypeNVMe DiskType = "NVMe"
+)
+
+// DiskSelector selects the disks the other disk rules apply to
+type DiskSelector struct {
+	// +optional
+	// HCTL selects the disks at the SCSI location, any location when unset
+	HCTL string `json:"hctl,omitempty"`
+	// +optional
+	// Rotational selects rotational disks when true and
+	// non-rotational disks when false. Both are selected when unset.
+	Rotational *bool `json:"rotational,omitempty"`
+	// +optional
+	// Type selects the disks of the given type, any type when unset
+	Type DiskType `json:"type,omitempty"`
+}
+
+// Disk contains the expected disk details
+type Disk struct {
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinimumCount int32 `json:"minimumCount,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaximumCount int32 `json:"maximumCount,omitempty"`
+	// +optional
+	// MinimumIndividualSize is the smallest size accepted for each disk
+	MinimumIndividualSize *resource.Quantity `json:"minimumIndividualSize,omitempty"`
+	// +optional
+	// MaximumIndividualSize is the largest size accepted for each disk
+	MaximumIndividualSize *resource.Quantity `json:"maximumIndividualSize,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// SizeTolerancePercent widens the size range by the given percentage
+	SizeTolerancePercent int32 `json:"sizeTolerancePercent,omitempty"`
+	// +optional
+	DiskSelector []DiskSelector `json:"diskSelector,omitempty"`
+}
+
+// NICSelector selects the NICs the other NIC rules apply to
+type NICSelector struct {
+	// +optional
+	Vendor []string `json:"vendor,omitempty"`
+}
+
+// NIC contains the expected NIC details
+type NIC struct {
+	// +optional
+	NICSelector NICSelector `json:"nicSelector,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinimumCount int32 `json:"minimumCount,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaximumCount int32 `json:"maximumCount,omitempty"`
+}
+
+// RAM contains the expected RAM details
+type RAM struct {
+	// +optional
+	MinimumSize *resource.Quantity `json:"minimumSize,omitempty"`
+	// +optional
+	MaximumSize *resource.Quantity `json:"maximumSize,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// SizeTolerancePercent widens the size range by the given percentage
+	SizeTolerancePercent int32 `json:"sizeTolerancePercent,omitempty"`
+}
+
+// ProfileMatchStatus represents the state of the HardwareClassification
+type ProfileMatchStatus string
+
+const (
+	// ProfileMatchStatusEmpty is the default status value
+	ProfileMatchStatusEmpty ProfileMatchStatus = ""
+	// ProfileMatchStatusMatched is the status value when the profile
+	// matches to one of the BareMetalHost.
+	ProfileMatchStatusMatched ProfileMatchStatus = "matched"
+	// ProfileMatchStatusUnMatched is the status value when the profile
+	// does not match to one of the BareMetalHost.
+	ProfileMatchStatusUnMatched ProfileMatchStatus = "unmatched"
+	// NoBareMetalHosts is the status value when the profile
+	// does not found no BareMetalHosts.
+	NoBareMetalHosts ProfileMatchStatus = "No BareMetalHosts Found"
+)
+
+// ErrorType indicates the class of problem that has caused the HCC resource
+// to enter an error state.
+type ErrorType string
+
+// Keys of the ErrorHosts status map. They are the error types reported
+// by the BareMetalHosts.
+const (
+	RegistrationError            = "registration error"
+	InspectionError              = "inspection error"
+	ProvisioningError            = "provisioning error"
+	PowerManagementError         = "power management error"
+	ProvisionedRegistrationError = "provisioned registration error"
+	PreparationError             = "preparation error"
+	DetachError                  = "detach error"
+)
+
+// FailingHost describes a host in error state
+type FailingHost struct {
+	// Name of the BareMetalHost
+	Name string `json:"name"`
+	// ErrorType reported by the host
+	ErrorType string `json:"errorType,omitempty"`
+	// ErrorMessage reported by the host
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// HostScore is the fit score of a host for a profile
+type HostScore struct {
+	// Name of the BareMetalHost
+	Name string `json:"name"`
+	// Score of the host in percent
+	Score int32 `json:"score"`
+}
+
+// HardwareClassificationStatus defines the observed state of HardwareClassification
+type HardwareClassificationStatus struct {
+	// ErrorType indicates the type of failure encountered
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	// ProfileMatchStatus identifies whether a applied profile is matches or not
+	ProfileMatchStatus ProfileMatchStatus `json:"profileMatchStatus,omitempty"`
+	// The count of hosts matching the profile
+	MatchedCount int32 `json:"matchedCount,omitempty"`
+	// The count of hosts not matching the profile
+	UnmatchedCount int32 `json:"unmatchedCount,omitempty"`
+	// The count of hosts in error state
+	ErrorHostCount int32 `json:"errorHostCount,omitempty"`
+	// The count of hosts in error state, by error type
+	ErrorHosts map[string]int32 `json:"errorHosts,omitempty"`
+	// The hosts in error state in name order, at most 20
+	FailingHosts []FailingHost `json:"failingHosts,omitempty"`
+	// The last error message reported by the hardwareclassification system
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// The best scoring hosts, highest score first, when scoring is enabled
+	HostScores []HostScore `json:"hostScores,omitempty"`
+	// The count of hosts matching the profile
+	EligibleCount int32 `json:"eligibleCount,omitempty"`
+	// The count of hosts labelled for the profile
+	LabelledCount int32 `json:"labelledCount,omitempty"`
+	// The names of the hosts selected for labelling when the profile
+	// sets MaxHosts
+	SelectedHosts []string `json:"selectedHosts,omitempty"`
+	// The names of the hosts which are labelled but no longer match
+	// the profile
+	StaleHosts []string `json:"staleHosts,omitempty"`
+	// The count of hosts labelled for the profile per provisioning
+	// state, hosts without state are counted as "none"
+	MatchedHostsByState map[string]int32 `json:"matchedHostsByState,omitempty"`
+	// The count of hosts labelled for the profile which have a consumer
+	ConsumedCount int32 `json:"consumedCount,omitempty"`
+	// The count of hosts labelled for the profile which are ready or
+	// available and have no consumer
+	AvailableCount int32 `json:"availableCount,omitempty"`
+	// The characteristics used to classify hosts, merged from the
+	// template and the profile, when the profile sets TemplateRef
+	EffectiveHardwareCharacteristics *HardwareCharacteristics `json:"effectiveHardwareCharacteristics,omitempty"`
+	// Conditions describe the state of the profile
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=hwc;hc
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ProfileMatchStatus",type="string",JSONPath=".status.profileMatchStatus",description="Profile Match Status"
+// +kubebuilder:printcolumn:name="MatchedHosts",type="integer",JSONPath=".status.matchedCount",description="Total Matched hosts."
+// +kubebuilder:printcolumn:name="UnmatchedHosts",type="integer",JSONPath=".status.unmatchedCount",description="Total Unmatched hosts."
+// +kubebuilder:printcolumn:name="ErrorHosts",type="integer",JSONPath=".status.errorHostCount",description="Total error hosts."
+// +kubebuilder:printcolumn:name="AvailableHosts",type="integer",JSONPath=".status.availableCount",description="Total matched hosts ready or available without consumer."
+// +kubebuilder:printcolumn:name="ConsumedHosts",type="integer",priority=1,JSONPath=".status.consumedCount",description="Total matched hosts with a consumer."
+// +kubebuilder:printcolumn:name="EligibleHosts",type="integer",priority=1,JSONPath=".status.eligibleCount",description="Total hosts matching the profile."
+// +kubebuilder:printcolumn:name="LabelledHosts",type="integer",priority=1,JSONPath=".status.labelledCount",description="Total hosts labelled for the profile."
+// +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.errorMessage",description="Most recent error"
+
+// HardwareClassification is the Schema for the hardwareclassifications API
+type HardwareClassification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HardwareClassificationSpec   `json:"spec,omitempty"`
+	Status HardwareClassificationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HardwareClassificationList contains a list of HardwareClassification
+type HardwareClassificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HardwareClassification `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HardwareClassification{}, &HardwareClassificationList{})
+}
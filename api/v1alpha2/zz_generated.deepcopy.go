@@ -0,0 +1,467 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BIOS) DeepCopyInto(out *BIOS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BIOS.
+func (in *BIOS) DeepCopy() *BIOS {
+	if in == nil {
+		return nil
+	}
+	out := new(BIOS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPU) DeepCopyInto(out *CPU) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPU.
+func (in *CPU) DeepCopy() *CPU {
+	if in == nil {
+		return nil
+	}
+	out := new(CPU)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Disk) DeepCopyInto(out *Disk) {
+	*out = *in
+	if in.MinimumIndividualSize != nil {
+		in, out := &in.MinimumIndividualSize, &out.MinimumIndividualSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaximumIndividualSize != nil {
+		in, out := &in.MaximumIndividualSize, &out.MaximumIndividualSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DiskSelector != nil {
+		in, out := &in.DiskSelector, &out.DiskSelector
+		*out = make([]DiskSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Disk.
+func (in *Disk) DeepCopy() *Disk {
+	if in == nil {
+		return nil
+	}
+	out := new(Disk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSelector) DeepCopyInto(out *DiskSelector) {
+	*out = *in
+	if in.Rotational != nil {
+		in, out := &in.Rotational, &out.Rotational
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSelector.
+func (in *DiskSelector) DeepCopy() *DiskSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailingHost) DeepCopyInto(out *FailingHost) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailingHost.
+func (in *FailingHost) DeepCopy() *FailingHost {
+	if in == nil {
+		return nil
+	}
+	out := new(FailingHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Firmware) DeepCopyInto(out *Firmware) {
+	*out = *in
+	out.BIOS = in.BIOS
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Firmware.
+func (in *Firmware) DeepCopy() *Firmware {
+	if in == nil {
+		return nil
+	}
+	out := new(Firmware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareCharacteristics) DeepCopyInto(out *HardwareCharacteristics) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(CPU)
+		**out = **in
+	}
+	if in.Disk != nil {
+		in, out := &in.Disk, &out.Disk
+		*out = new(Disk)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NIC != nil {
+		in, out := &in.NIC, &out.NIC
+		*out = new(NIC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RAM != nil {
+		in, out := &in.RAM, &out.RAM
+		*out = new(RAM)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SystemVendor != nil {
+		in, out := &in.SystemVendor, &out.SystemVendor
+		*out = new(SystemVendor)
+		**out = **in
+	}
+	if in.Firmware != nil {
+		in, out := &in.Firmware, &out.Firmware
+		*out = new(Firmware)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareCharacteristics.
+func (in *HardwareCharacteristics) DeepCopy() *HardwareCharacteristics {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareCharacteristics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassification) DeepCopyInto(out *HardwareClassification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassification.
+func (in *HardwareClassification) DeepCopy() *HardwareClassification {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareClassification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassificationList) DeepCopyInto(out *HardwareClassificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HardwareClassification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationList.
+func (in *HardwareClassificationList) DeepCopy() *HardwareClassificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HardwareClassificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassificationSpec) DeepCopyInto(out *HardwareClassificationSpec) {
+	*out = *in
+	in.HardwareCharacteristics.DeepCopyInto(&out.HardwareCharacteristics)
+	if in.TemplateRef != nil {
+		in, out := &in.TemplateRef, &out.TemplateRef
+		*out = new(TemplateReference)
+		**out = **in
+	}
+	if in.Scoring != nil {
+		in, out := &in.Scoring, &out.Scoring
+		*out = new(Scoring)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationSpec.
+func (in *HardwareClassificationSpec) DeepCopy() *HardwareClassificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareClassificationStatus) DeepCopyInto(out *HardwareClassificationStatus) {
+	*out = *in
+	if in.ErrorHosts != nil {
+		in, out := &in.ErrorHosts, &out.ErrorHosts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FailingHosts != nil {
+		in, out := &in.FailingHosts, &out.FailingHosts
+		*out = make([]FailingHost, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostScores != nil {
+		in, out := &in.HostScores, &out.HostScores
+		*out = make([]HostScore, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectedHosts != nil {
+		in, out := &in.SelectedHosts, &out.SelectedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StaleHosts != nil {
+		in, out := &in.StaleHosts, &out.StaleHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MatchedHostsByState != nil {
+		in, out := &in.MatchedHostsByState, &out.MatchedHostsByState
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EffectiveHardwareCharacteristics != nil {
+		in, out := &in.EffectiveHardwareCharacteristics, &out.EffectiveHardwareCharacteristics
+		*out = new(HardwareCharacteristics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareClassificationStatus.
+func (in *HardwareClassificationStatus) DeepCopy() *HardwareClassificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareClassificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostScore) DeepCopyInto(out *HostScore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostScore.
+func (in *HostScore) DeepCopy() *HostScore {
+	if in == nil {
+		return nil
+	}
+	out := new(HostScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NIC) DeepCopyInto(out *NIC) {
+	*out = *in
+	in.NICSelector.DeepCopyInto(&out.NICSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NIC.
+func (in *NIC) DeepCopy() *NIC {
+	if in == nil {
+		return nil
+	}
+	out := new(NIC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NICSelector) DeepCopyInto(out *NICSelector) {
+	*out = *in
+	if in.Vendor != nil {
+		in, out := &in.Vendor, &out.Vendor
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICSelector.
+func (in *NICSelector) DeepCopy() *NICSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NICSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RAM) DeepCopyInto(out *RAM) {
+	*out = *in
+	if in.MinimumSize != nil {
+		in, out := &in.MinimumSize, &out.MinimumSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaximumSize != nil {
+		in, out := &in.MaximumSize, &out.MaximumSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RAM.
+func (in *RAM) DeepCopy() *RAM {
+	if in == nil {
+		return nil
+	}
+	out := new(RAM)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scoring) DeepCopyInto(out *Scoring) {
+	*out = *in
+	out.Weights = in.Weights
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scoring.
+func (in *Scoring) DeepCopy() *Scoring {
+	if in == nil {
+		return nil
+	}
+	out := new(Scoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScoringWeights) DeepCopyInto(out *ScoringWeights) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScoringWeights.
+func (in *ScoringWeights) DeepCopy() *ScoringWeights {
+	if in == nil {
+		return nil
+	}
+	out := new(ScoringWeights)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemVendor) DeepCopyInto(out *SystemVendor) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemVendor.
+func (in *SystemVendor) DeepCopy() *SystemVendor {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemVendor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateReference) DeepCopyInto(out *TemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateReference.
+func (in *TemplateReference) DeepCopy() *TemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
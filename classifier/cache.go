@@ -0,0 +1,220 @@
+package classifier
+
+import (
+	"sync"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+var (
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "hwcc_classification_cache_lookups_total",
+		Help: "Number of classification results looked up in the cache, by result (hit or miss).",
+	}, []string{"result"})
+	cacheInvalidations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hwcc_classification_cache_invalidations_total",
+		Help: "Number of cached classification results dropped because the hardware of the host or the profile changed.",
+	})
+	cacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "hwcc_classification_cache_entries",
+		Help: "Number of host and profile pairs with cached classification results.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cacheLookups, cacheInvalidations, cacheEntries)
+}
+
+// DefaultCache is the classification result cache shared by the
+// controllers.
+var DefaultCache = NewCache()
+
+// Cache keeps the classification results of host and profile pairs
+// for as long as the hardware details of the host and the generation
+// of the profile do not change.
+//
+// Hosts and profiles without UID, which were not read from the API
+// server, and profiles referencing a template, whose characteristics
+// may change without a new generation, are always classified again.
+type Cache struct {
+	mu      sync.Mutex
+	results map[resultKey]*result
+	hashes  map[types.UID]hostHash
+}
+
+type resultKey struct {
+	host    types.UID
+	profile types.UID
+}
+
+// resultVersion identifies the inputs the results were computed from.
+type resultVersion struct {
+	hardwareHash string
+	generation   int64
+}
+
+type result struct {
+	version resultVersion
+	matches *bool
+	score   *int
+}
+
+// hostHash remembers the hardware hash of a version of a host, as
+// computing it costs about as much as classifying the host.
+type hostHash struct {
+	resourceVersion string
+	hash            string
+}
+
+// NewCache returns an empty classification result cache.
+func NewCache() *Cache {
+	return &Cache{
+		results: map[resultKey]*result{},
+		hashes:  map[types.UID]hostHash{},
+	}
+}
+
+// ProfileMatchesHost is the cached version of ProfileMatchesHost.
+func (c *Cache) ProfileMatchesHost(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bool {
+	key, version, ok := c.key(profile, host)
+	if !ok {
+		return ProfileMatchesHost(profile, host)
+	}
+	if r := c.get(key, version); r != nil && r.matches != nil {
+		cacheLookups.WithLabelValues("hit").Inc()
+		return *r.matches
+	}
+
+	cacheLookups.WithLabelValues("miss").Inc()
+	matches := ProfileMatchesHost(profile, host)
+	c.set(key, version, func(r *result) { r.matches = &matches })
+	return matches
+}
+
+// ProfileScoreForHost is the cached version of ProfileScoreForHost.
+func (c *Cache) ProfileScoreForHost(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) int {
+	key, version, ok := c.key(profile, host)
+	if !ok {
+		return ProfileScoreForHost(profile, host)
+	}
+	if r := c.get(key, version); r != nil && r.score != nil {
+		cacheLookups.WithLabelValues("hit").Inc()
+		return *r.score
+	}
+
+	cacheLookups.WithLabelValues("miss").Inc()
+	score := ProfileScoreForHost(profile, host)
+	c.set(key, version, func(r *result) { r.score = &score })
+	return score
+}
+
+// ForgetHost drops the results cached for the host.
+func (c *Cache) ForgetHost(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.hashes, uid)
+	for key := range c.results {
+		if key.host == uid {
+			c.remove(key)
+		}
+	}
+}
+
+// ForgetProfile drops the results cached for the profile.
+func (c *Cache) ForgetProfile(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.results {
+		if key.profile == uid {
+			c.remove(key)
+		}
+	}
+}
+
+// Len returns the number of host and profile pairs with cached
+// results.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.results)
+}
+
+// key returns the cache key of the pair and the version of its
+// inputs, or false when the results of the pair cannot be cached.
+func (c *Cache) key(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) (resultKey, resultVersion, bool) {
+	if host.UID == "" || profile.UID == "" || profile.Spec.TemplateRef != nil ||
+		host.Status.HardwareDetails == nil {
+		return resultKey{}, resultVersion{}, false
+	}
+	key := resultKey{host: host.UID, profile: profile.UID}
+	return key, resultVersion{hardwareHash: c.hardwareHash(host), generation: profile.Generation}, true
+}
+
+func (c *Cache) hardwareHash(host *bmh.BareMetalHost) string {
+	if host.ResourceVersion == "" {
+		return HardwareHash(host)
+	}
+
+	c.mu.Lock()
+	cached, ok := c.hashes[host.UID]
+	c.mu.Unlock()
+	if ok && cached.resourceVersion == host.ResourceVersion {
+		return cached.hash
+	}
+
+	hash := HardwareHash(host)
+	c.mu.Lock()
+	c.hashes[host.UID] = hostHash{resourceVersion: host.ResourceVersion, hash: hash}
+	c.mu.Unlock()
+	return hash
+}
+
+// get returns a copy of the results cached for the pair, dropping
+// them when they were computed from other inputs.
+func (c *Cache) get(key resultKey, version resultVersion) *result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.results[key]
+	if !ok {
+		return nil
+	}
+	if r.version != version {
+		cacheInvalidations.Inc()
+		c.remove(key)
+		return nil
+	}
+	copied := *r
+	return &copied
+}
+
+// set updates the results cached for the pair. Results are computed
+// without holding the lock, so the cached ones may have been replaced
+// in between.
+func (c *Cache) set(key resultKey, version resultVersion, update func(*result)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.results[key]
+	if !ok || r.version != version {
+		if !ok {
+			cacheEntries.Inc()
+		}
+		r = &result{version: version}
+		c.results[key] = r
+	}
+	update(r)
+}
+
+// remove drops the results of the pair, the lock must be held.
+func (c *Cache) remove(key resultKey) {
+	delete(c.results, key)
+	cacheEntries.Dec()
+}
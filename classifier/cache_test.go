@@ -0,0 +1,146 @@
+package classifier
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func cacheTestObjects() (*hwcc.HardwareClassification, *bmh.BareMetalHost) {
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", UID: "profile-uid", Generation: 1},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+			},
+		},
+	}
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "host", UID: "host-uid", ResourceVersion: "1"},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				CPU: bmh.CPU{Count: 16},
+			},
+		},
+	}
+	return profile, host
+}
+
+func TestCacheProfileMatchesHost(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		// Update changes the objects after the first classification
+		// without changing whether they match.
+		Update  func(*hwcc.HardwareClassification, *bmh.BareMetalHost)
+		Matches bool
+		Cached  int
+	}{
+		{
+			Scenario: "unchanged",
+			Update:   func(*hwcc.HardwareClassification, *bmh.BareMetalHost) {},
+			Matches:  true,
+			Cached:   1,
+		},
+		{
+			Scenario: "same-generation",
+			Update: func(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) {
+				profile.Spec.HardwareCharacteristics.Cpu.MinimumCount = 32
+			},
+			Matches: true,
+			Cached:  1,
+		},
+		{
+			Scenario: "new-generation",
+			Update: func(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) {
+				profile.Spec.HardwareCharacteristics.Cpu.MinimumCount = 32
+				profile.Generation++
+			},
+			Matches: false,
+			Cached:  1,
+		},
+		{
+			Scenario: "same-hardware",
+			Update: func(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) {
+				host.ResourceVersion = "2"
+				host.Status.HardwareDetails.Hostname = "renamed"
+			},
+			Matches: true,
+			Cached:  1,
+		},
+		{
+			Scenario: "new-hardware",
+			Update: func(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) {
+				host.ResourceVersion = "2"
+				host.Status.HardwareDetails.CPU.Count = 4
+			},
+			Matches: false,
+			Cached:  1,
+		},
+		{
+			Scenario: "template",
+			Update: func(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) {
+				profile.Spec.TemplateRef = &hwcc.TemplateReference{Name: "template"}
+				profile.Spec.HardwareCharacteristics.Cpu.MinimumCount = 32
+			},
+			Matches: false,
+			Cached:  1,
+		},
+		{
+			Scenario: "no-uid",
+			Update: func(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) {
+				host.UID = ""
+				host.Status.HardwareDetails.CPU.Count = 4
+			},
+			Matches: false,
+			Cached:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			cache := NewCache()
+			profile, host := cacheTestObjects()
+			assert.True(t, cache.ProfileMatchesHost(profile, host))
+
+			tc.Update(profile, host)
+			assert.Equal(t, tc.Matches, cache.ProfileMatchesHost(profile, host))
+			assert.Equal(t, tc.Cached, cache.Len())
+		})
+	}
+}
+
+func TestCacheProfileScoreForHost(t *testing.T) {
+	cache := NewCache()
+	profile, host := cacheTestObjects()
+	profile.Spec.Scoring = &hwcc.Scoring{MinimumScore: 50}
+	assert.Equal(t, 100, cache.ProfileScoreForHost(profile, host))
+	assert.True(t, cache.ProfileMatchesHost(profile, host))
+
+	// Without a new resource version the hardware hash of the host
+	// is not computed again.
+	host.Status.HardwareDetails.CPU.Count = 4
+	assert.Equal(t, 100, cache.ProfileScoreForHost(profile, host))
+	assert.True(t, cache.ProfileMatchesHost(profile, host))
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestCacheForget(t *testing.T) {
+	cache := NewCache()
+	profile, host := cacheTestObjects()
+	other := profile.DeepCopy()
+	other.UID = "other-uid"
+
+	cache.ProfileMatchesHost(profile, host)
+	cache.ProfileMatchesHost(other, host)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.ForgetProfile(other.UID)
+	assert.Equal(t, 1, cache.Len())
+
+	cache.ForgetHost(host.UID)
+	assert.Equal(t, 0, cache.Len())
+}
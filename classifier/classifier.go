@@ -9,24 +9,89 @@ import (
 
 var log = ctrl.Log.WithName("classifier")
 
-func ProfileMatchesHost(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bool {
-	if !checkSystemVendor(profile, host) {
-		return false
-	}
-	if !checkFirmware(profile, host) {
-		return false
-	}
-	if !checkCPU(profile, host) {
-		return false
-	}
-	if !checkRAM(profile, host) {
-		return false
+// valueRange is the expected value of range checks in the logs.
+type valueRange struct {
+	Min interface{} `json:"min"`
+	Max interface{} `json:"max"`
+}
+
+// logCheck logs the result of a single check. Checks run for every
+// host and profile pair, so they are only logged at verbosity 1.
+func logCheck(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost,
+	check string, expected, actual interface{}, ok bool) {
+	debug := log.V(1)
+	if !debug.Enabled() {
+		return
 	}
-	if !checkNICs(profile, host) {
-		return false
+	debug.Info("checked host",
+		"host", host.Name,
+		"namespace", host.Namespace,
+		"profile", profile.Name,
+		"check", check,
+		"expected", expected,
+		"actual", actual,
+		"ok", ok,
+	)
+}
+
+// characteristic describes one group of hardware characteristics of a
+// profile and how to check it against a host.
+type characteristic struct {
+	// isSet reports whether the profile has rules for the
+	// characteristic.
+	isSet func(*hwcc.HardwareCharacteristics) bool
+	// weight returns the configured weight of the characteristic.
+	weight func(*hwcc.ScoringWeights) int
+	// check reports whether the host satisfies the rules.
+	check func(*hwcc.HardwareClassification, *bmh.BareMetalHost) bool
+}
+
+// characteristics lists the checks in the order they are evaluated.
+var characteristics = []characteristic{
+	{
+		isSet:  func(hc *hwcc.HardwareCharacteristics) bool { return hc.SystemVendor != nil },
+		weight: func(w *hwcc.ScoringWeights) int { return w.SystemVendor },
+		check:  checkSystemVendor,
+	},
+	{
+		isSet:  func(hc *hwcc.HardwareCharacteristics) bool { return hc.Firmware != nil },
+		weight: func(w *hwcc.ScoringWeights) int { return w.Firmware },
+		check:  checkFirmware,
+	},
+	{
+		isSet:  func(hc *hwcc.HardwareCharacteristics) bool { return hc.Cpu != nil },
+		weight: func(w *hwcc.ScoringWeights) int { return w.Cpu },
+		check:  checkCPU,
+	},
+	{
+		isSet:  func(hc *hwcc.HardwareCharacteristics) bool { return hc.Ram != nil },
+		weight: func(w *hwcc.ScoringWeights) int { return w.Ram },
+		check:  checkRAM,
+	},
+	{
+		isSet:  func(hc *hwcc.HardwareCharacteristics) bool { return hc.Nic != nil },
+		weight: func(w *hwcc.ScoringWeights) int { return w.Nic },
+		check:  checkNICs,
+	},
+	{
+		isSet:  func(hc *hwcc.HardwareCharacteristics) bool { return hc.Disk != nil },
+		weight: func(w *hwcc.ScoringWeights) int { return w.Disk },
+		check:  checkDisks,
+	},
+}
+
+// ProfileMatchesHost reports whether the host matches the profile.
+// When the profile enables scoring the host matches if its fit score
+// reaches the minimum score of the profile, otherwise every
+// characteristic of the profile has to match.
+func ProfileMatchesHost(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bool {
+	if profile.Spec.Scoring != nil {
+		return ProfileScoreForHost(profile, host) >= profile.Spec.Scoring.MinimumScore
 	}
-	if !checkDisks(profile, host) {
-		return false
+	for _, c := range characteristics {
+		if !c.check(profile, host) {
+			return false
+		}
 	}
 	return true
 }
@@ -0,0 +1,244 @@
+package classifier
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// Coverage classifies the hosts against the profiles and reports the
+// inspected hosts matching no profile or several profiles, and the
+// profiles strictly subsumed by another one. Profiles are reported by
+// name.
+func (e *Engine) Coverage(ctx context.Context, profiles []hwcc.HardwareClassification, hosts []bmh.BareMetalHost) (*hwcc.ProfileCoverage, error) {
+	results, err := e.Classify(ctx, profiles, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &hwcc.ProfileCoverage{ProfileCount: len(profiles)}
+	for h := range hosts {
+		if hosts[h].Status.HardwareDetails == nil {
+			continue
+		}
+		matching := []string{}
+		for p := range results {
+			if results[p].Hosts[h].Matches {
+				matching = append(matching, profiles[p].Name)
+			}
+		}
+		switch {
+		case len(matching) == 0:
+			coverage.UnclassifiedHosts = append(coverage.UnclassifiedHosts, hosts[h].Name)
+		case len(matching) > 1:
+			sort.Strings(matching)
+			coverage.OverlappingHosts = append(coverage.OverlappingHosts, hwcc.HostOverlap{
+				Host:     hosts[h].Name,
+				Profiles: matching,
+			})
+		}
+	}
+	sort.Strings(coverage.UnclassifiedHosts)
+	sort.Slice(coverage.OverlappingHosts, func(i, j int) bool {
+		return coverage.OverlappingHosts[i].Host < coverage.OverlappingHosts[j].Host
+	})
+	coverage.UnclassifiedCount = len(coverage.UnclassifiedHosts)
+	coverage.OverlappingCount = len(coverage.OverlappingHosts)
+
+	for inner := range profiles {
+		for outer := range profiles {
+			if inner == outer {
+				continue
+			}
+			basis, ok := subsumption(&results[outer], &results[inner])
+			if !ok {
+				continue
+			}
+			coverage.SubsumedProfiles = append(coverage.SubsumedProfiles, hwcc.ProfileSubsumption{
+				Profile:    profiles[inner].Name,
+				SubsumedBy: profiles[outer].Name,
+				Basis:      basis,
+			})
+		}
+	}
+	sort.Slice(coverage.SubsumedProfiles, func(i, j int) bool {
+		a, b := coverage.SubsumedProfiles[i], coverage.SubsumedProfiles[j]
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+		return a.SubsumedBy < b.SubsumedBy
+	})
+	return coverage, nil
+}
+
+// subsumption reports whether the inner profile is strictly subsumed by
+// the outer one, either because every host matching the rules of the
+// inner profile also matches the looser rules of the outer one, or
+// because the hosts matching the inner profile are some of the hosts
+// matching the outer one.
+func subsumption(outer, inner *ProfileResults) (hwcc.SubsumptionBasis, bool) {
+	if CharacteristicsSubsume(outer.Profile, inner.Profile) &&
+		!CharacteristicsSubsume(inner.Profile, outer.Profile) {
+		return hwcc.SubsumptionBasisCharacteristics, true
+	}
+
+	innerCount, outerCount := 0, 0
+	for h := range inner.Hosts {
+		if outer.Hosts[h].Matches {
+			outerCount++
+		}
+		if !inner.Hosts[h].Matches {
+			continue
+		}
+		if !outer.Hosts[h].Matches {
+			return "", false
+		}
+		innerCount++
+	}
+	if innerCount > 0 && innerCount < outerCount {
+		return hwcc.SubsumptionBasisHosts, true
+	}
+	return "", false
+}
+
+// CharacteristicsSubsume reports whether every host matching the inner
+// profile matches the outer profile, judging from their rules alone.
+// Profiles using scoring are never subsumed nor subsume others. In the
+// checks of each characteristic a rule which is not set matches every
+// host.
+func CharacteristicsSubsume(outer, inner *hwcc.HardwareClassification) bool {
+	if outer.Spec.Scoring != nil || inner.Spec.Scoring != nil {
+		return false
+	}
+	o, i := outer.Spec.HardwareCharacteristics, inner.Spec.HardwareCharacteristics
+	return cpuSubsumes(o.Cpu, i.Cpu) &&
+		ramSubsumes(o.Ram, i.Ram) &&
+		diskSubsumes(o.Disk, i.Disk) &&
+		nicSubsumes(o.Nic, i.Nic) &&
+		systemVendorSubsumes(o.SystemVendor, i.SystemVendor) &&
+		firmwareSubsumes(o.Firmware, i.Firmware)
+}
+
+// rangeSubsumes reports whether the outer range contains the inner
+// range, a zero bound being unbounded.
+func rangeSubsumes(outerMin, outerMax, innerMin, innerMax int64) bool {
+	if outerMin > 0 && innerMin < outerMin {
+		return false
+	}
+	if outerMax > 0 && (innerMax == 0 || innerMax > outerMax) {
+		return false
+	}
+	return true
+}
+
+func cpuSubsumes(outer, inner *hwcc.Cpu) bool {
+	if outer == nil {
+		return true
+	}
+	if inner == nil {
+		inner = &hwcc.Cpu{}
+	}
+	return (outer.Architecture == "" || outer.Architecture == inner.Architecture) &&
+		rangeSubsumes(int64(outer.MinimumCount), int64(outer.MaximumCount),
+			int64(inner.MinimumCount), int64(inner.MaximumCount)) &&
+		rangeSubsumes(int64(outer.MinimumSpeedMHz), int64(outer.MaximumSpeedMHz),
+			int64(inner.MinimumSpeedMHz), int64(inner.MaximumSpeedMHz))
+}
+
+func ramSubsumes(outer, inner *hwcc.Ram) bool {
+	if outer == nil {
+		return true
+	}
+	if inner == nil {
+		inner = &hwcc.Ram{}
+	}
+	outerMin, outerMax := sizeBounds(outer.MinimumSize, outer.MaximumSize,
+		int64(outer.MinimumSizeGB), int64(outer.MaximumSizeGB), int64(bmh.GibiByte), outer.SizeTolerancePercent)
+	innerMin, innerMax := sizeBounds(inner.MinimumSize, inner.MaximumSize,
+		int64(inner.MinimumSizeGB), int64(inner.MaximumSizeGB), int64(bmh.GibiByte), inner.SizeTolerancePercent)
+	return rangeSubsumes(int64(outerMin), int64(outerMax), int64(innerMin), int64(innerMax))
+}
+
+// diskSubsumes only compares profiles selecting the same disks, as the
+// counts and sizes apply to the selected disks.
+func diskSubsumes(outer, inner *hwcc.Disk) bool {
+	if outer == nil {
+		return true
+	}
+	if inner == nil {
+		inner = &hwcc.Disk{}
+	}
+	if (len(outer.DiskSelector) > 0 || len(inner.DiskSelector) > 0) &&
+		!reflect.DeepEqual(outer.DiskSelector, inner.DiskSelector) {
+		return false
+	}
+	outerMin, outerMax := sizeBounds(outer.MinimumIndividualSize, outer.MaximumIndividualSize,
+		outer.MinimumIndividualSizeGB, outer.MaximumIndividualSizeGB, int64(bmh.GigaByte), outer.SizeTolerancePercent)
+	innerMin, innerMax := sizeBounds(inner.MinimumIndividualSize, inner.MaximumIndividualSize,
+		inner.MinimumIndividualSizeGB, inner.MaximumIndividualSizeGB, int64(bmh.GigaByte), inner.SizeTolerancePercent)
+	return rangeSubsumes(int64(outer.MinimumCount), int64(outer.MaximumCount),
+		int64(inner.MinimumCount), int64(inner.MaximumCount)) &&
+		rangeSubsumes(int64(outerMin), int64(outerMax), int64(innerMin), int64(innerMax))
+}
+
+// nicSubsumes requires the inner profile to require all the vendors
+// required by the outer profile.
+func nicSubsumes(outer, inner *hwcc.Nic) bool {
+	if outer == nil {
+		return true
+	}
+	if inner == nil {
+		inner = &hwcc.Nic{}
+	}
+	for _, vendor := range outer.NicSelector.Vendor {
+		found := false
+		for _, innerVendor := range inner.NicSelector.Vendor {
+			found = found || vendor == innerVendor
+		}
+		if !found {
+			return false
+		}
+	}
+	return rangeSubsumes(int64(outer.MinimumCount), int64(outer.MaximumCount),
+		int64(inner.MinimumCount), int64(inner.MaximumCount))
+}
+
+// systemVendorSubsumes follows checkSystemVendor: the product name of
+// the host only has to contain the product name of the profile.
+func systemVendorSubsumes(outer, inner *hwcc.SystemVendor) bool {
+	if outer == nil {
+		return true
+	}
+	if inner == nil {
+		inner = &hwcc.SystemVendor{}
+	}
+	return (outer.Manufacturer == "" || outer.Manufacturer == inner.Manufacturer) &&
+		(outer.ProductName == "" || strings.Contains(inner.ProductName, outer.ProductName))
+}
+
+// firmwareSubsumes follows checkFirmware: the version range only
+// applies when both of its bounds are set.
+func firmwareSubsumes(outer, inner *hwcc.Firmware) bool {
+	if outer == nil {
+		return true
+	}
+	if inner == nil {
+		inner = &hwcc.Firmware{}
+	}
+	if outer.BIOS.Vendor != "" && outer.BIOS.Vendor != inner.BIOS.Vendor {
+		return false
+	}
+	if outer.BIOS.MinorVersion == "" || outer.BIOS.MajorVersion == "" {
+		return true
+	}
+	if inner.BIOS.MinorVersion == "" || inner.BIOS.MajorVersion == "" {
+		return false
+	}
+	return appendZeros(inner.BIOS.MinorVersion, 4) >= appendZeros(outer.BIOS.MinorVersion, 4) &&
+		appendZeros(inner.BIOS.MajorVersion, 4) <= appendZeros(outer.BIOS.MajorVersion, 4)
+}
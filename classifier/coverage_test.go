@@ -0,0 +1,191 @@
+package classifier
+
+import (
+	"context"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func coverageProfile(name string, characteristics hwcc.HardwareCharacteristics) hwcc.HardwareClassification {
+	return hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       hwcc.HardwareClassificationSpec{HardwareCharacteristics: characteristics},
+	}
+}
+
+func TestCharacteristicsSubsume(t *testing.T) {
+	quantity := func(value string) *resource.Quantity {
+		q := resource.MustParse(value)
+		return &q
+	}
+	rotational := true
+
+	testCases := []struct {
+		Scenario string
+		Outer    hwcc.HardwareCharacteristics
+		Inner    hwcc.HardwareCharacteristics
+		Expected bool
+	}{
+		{
+			Scenario: "no-rules",
+			Inner:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{MinimumCount: 8}},
+			Expected: true,
+		},
+		{
+			Scenario: "rule-not-in-inner",
+			Outer:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{MinimumCount: 8}},
+			Expected: false,
+		},
+		{
+			Scenario: "wider-cpu-count",
+			Outer:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{MinimumCount: 8}},
+			Inner:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{MinimumCount: 16, MaximumCount: 32}},
+			Expected: true,
+		},
+		{
+			Scenario: "unbounded-inner-maximum",
+			Outer:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{MinimumCount: 8, MaximumCount: 64}},
+			Inner:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{MinimumCount: 16}},
+			Expected: false,
+		},
+		{
+			Scenario: "cpu-architecture",
+			Outer:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{Architecture: "x86_64"}},
+			Inner:    hwcc.HardwareCharacteristics{Cpu: &hwcc.Cpu{Architecture: "x86_64", MinimumCount: 8}},
+			Expected: true,
+		},
+		{
+			Scenario: "ram-quantity-and-legacy-size",
+			Outer:    hwcc.HardwareCharacteristics{Ram: &hwcc.Ram{MinimumSizeGB: 64}},
+			Inner:    hwcc.HardwareCharacteristics{Ram: &hwcc.Ram{MinimumSize: quantity("128Gi")}},
+			Expected: true,
+		},
+		{
+			Scenario: "ram-tolerance",
+			Outer:    hwcc.HardwareCharacteristics{Ram: &hwcc.Ram{MinimumSize: quantity("128Gi")}},
+			Inner:    hwcc.HardwareCharacteristics{Ram: &hwcc.Ram{MinimumSize: quantity("128Gi"), SizeTolerancePercent: 5}},
+			Expected: false,
+		},
+		{
+			Scenario: "same-disk-selectors",
+			Outer: hwcc.HardwareCharacteristics{Disk: &hwcc.Disk{
+				MinimumCount: 1,
+				DiskSelector: []hwcc.DiskSelector{{Rotational: &rotational}},
+			}},
+			Inner: hwcc.HardwareCharacteristics{Disk: &hwcc.Disk{
+				MinimumCount:          2,
+				MinimumIndividualSize: quantity("1T"),
+				DiskSelector:          []hwcc.DiskSelector{{Rotational: &rotational}},
+			}},
+			Expected: true,
+		},
+		{
+			Scenario: "other-disk-selectors",
+			Outer:    hwcc.HardwareCharacteristics{Disk: &hwcc.Disk{MinimumCount: 1}},
+			Inner: hwcc.HardwareCharacteristics{Disk: &hwcc.Disk{
+				MinimumCount: 2,
+				DiskSelector: []hwcc.DiskSelector{{Type: hwcc.DiskTypeNVMe}},
+			}},
+			Expected: false,
+		},
+		{
+			Scenario: "more-nic-vendors",
+			Outer:    hwcc.HardwareCharacteristics{Nic: &hwcc.Nic{NicSelector: hwcc.NicSelector{Vendor: []string{"0x8086"}}}},
+			Inner:    hwcc.HardwareCharacteristics{Nic: &hwcc.Nic{NicSelector: hwcc.NicSelector{Vendor: []string{"0x8086", "0x14e4"}}}},
+			Expected: true,
+		},
+		{
+			Scenario: "fewer-nic-vendors",
+			Outer:    hwcc.HardwareCharacteristics{Nic: &hwcc.Nic{NicSelector: hwcc.NicSelector{Vendor: []string{"0x8086", "0x14e4"}}}},
+			Inner:    hwcc.HardwareCharacteristics{Nic: &hwcc.Nic{NicSelector: hwcc.NicSelector{Vendor: []string{"0x8086"}}}},
+			Expected: false,
+		},
+		{
+			Scenario: "product-name-substring",
+			Outer:    hwcc.HardwareCharacteristics{SystemVendor: &hwcc.SystemVendor{ProductName: "PowerEdge"}},
+			Inner:    hwcc.HardwareCharacteristics{SystemVendor: &hwcc.SystemVendor{ProductName: "PowerEdge R640"}},
+			Expected: true,
+		},
+		{
+			Scenario: "firmware-version-range",
+			Outer: hwcc.HardwareCharacteristics{Firmware: &hwcc.Firmware{BIOS: hwcc.BIOS{
+				MinorVersion: "1.0.0", MajorVersion: "3.0.0",
+			}}},
+			Inner: hwcc.HardwareCharacteristics{Firmware: &hwcc.Firmware{BIOS: hwcc.BIOS{
+				MinorVersion: "1.5.6", MajorVersion: "2.5.6",
+			}}},
+			Expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			outer := coverageProfile("outer", tc.Outer)
+			inner := coverageProfile("inner", tc.Inner)
+			assert.Equal(t, tc.Expected, CharacteristicsSubsume(&outer, &inner))
+		})
+	}
+}
+
+func TestEngineCoverage(t *testing.T) {
+	host := func(name string, cpuCount int, manufacturer string) bmh.BareMetalHost {
+		return bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					SystemVendor: bmh.HardwareSystemVendor{Manufacturer: manufacturer},
+					CPU:          bmh.CPU{Count: cpuCount},
+				},
+			},
+		}
+	}
+	hosts := []bmh.BareMetalHost{
+		host("dell-8", 8, "Dell Inc."),
+		host("dell-32", 32, "Dell Inc."),
+		host("dell-64", 64, "Dell Inc."),
+		host("hpe-32", 32, "HPE"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "not-inspected"}},
+	}
+	profiles := []hwcc.HardwareClassification{
+		coverageProfile("large", hwcc.HardwareCharacteristics{
+			Cpu: &hwcc.Cpu{MinimumCount: 32},
+		}),
+		coverageProfile("large-dell", hwcc.HardwareCharacteristics{
+			Cpu:          &hwcc.Cpu{MinimumCount: 32},
+			SystemVendor: &hwcc.SystemVendor{Manufacturer: "Dell Inc."},
+		}),
+		coverageProfile("dell-64", hwcc.HardwareCharacteristics{
+			SystemVendor: &hwcc.SystemVendor{Manufacturer: "Dell Inc."},
+			Cpu:          &hwcc.Cpu{MinimumCount: 48, MaximumCount: 96},
+		}),
+		coverageProfile("hpe", hwcc.HardwareCharacteristics{
+			SystemVendor: &hwcc.SystemVendor{Manufacturer: "HPE"},
+		}),
+	}
+
+	coverage, err := NewEngine(1, nil).Coverage(context.TODO(), profiles, hosts)
+	assert.NoError(t, err)
+	assert.Equal(t, &hwcc.ProfileCoverage{
+		ProfileCount:      4,
+		UnclassifiedCount: 1,
+		UnclassifiedHosts: []string{"dell-8"},
+		OverlappingCount:  3,
+		OverlappingHosts: []hwcc.HostOverlap{
+			{Host: "dell-32", Profiles: []string{"large", "large-dell"}},
+			{Host: "dell-64", Profiles: []string{"dell-64", "large", "large-dell"}},
+			{Host: "hpe-32", Profiles: []string{"hpe", "large"}},
+		},
+		SubsumedProfiles: []hwcc.ProfileSubsumption{
+			{Profile: "dell-64", SubsumedBy: "large", Basis: hwcc.SubsumptionBasisCharacteristics},
+			{Profile: "dell-64", SubsumedBy: "large-dell", Basis: hwcc.SubsumptionBasisCharacteristics},
+			{Profile: "hpe", SubsumedBy: "large", Basis: hwcc.SubsumptionBasisHosts},
+			{Profile: "large-dell", SubsumedBy: "large", Basis: hwcc.SubsumptionBasisCharacteristics},
+		},
+	}, coverage)
+}
@@ -17,15 +17,9 @@ func checkCPU(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) boo
 		cpuDetails.MinimumCount,
 		cpuDetails.MaximumCount,
 		host.Status.HardwareDetails.CPU.Count)
-	log.Info("CPU",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"minCount", cpuDetails.MinimumCount,
-		"maxCount", cpuDetails.MaximumCount,
-		"actualCount", host.Status.HardwareDetails.CPU.Count,
-		"ok", ok,
-	)
+	logCheck(profile, host, "cpu-count",
+		valueRange{cpuDetails.MinimumCount, cpuDetails.MaximumCount},
+		host.Status.HardwareDetails.CPU.Count, ok)
 	if !ok {
 		return false
 	}
@@ -34,15 +28,9 @@ func checkCPU(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) boo
 		bmh.ClockSpeed(cpuDetails.MinimumSpeedMHz),
 		bmh.ClockSpeed(cpuDetails.MaximumSpeedMHz),
 		host.Status.HardwareDetails.CPU.ClockMegahertz)
-	log.Info("CPU",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"minSpeed", cpuDetails.MinimumSpeedMHz,
-		"maxSpeed", cpuDetails.MaximumSpeedMHz,
-		"actualSpeed", host.Status.HardwareDetails.CPU.ClockMegahertz,
-		"ok", ok,
-	)
+	logCheck(profile, host, "cpu-speed",
+		valueRange{cpuDetails.MinimumSpeedMHz, cpuDetails.MaximumSpeedMHz},
+		host.Status.HardwareDetails.CPU.ClockMegahertz, ok)
 	if !ok {
 		return false
 	}
@@ -50,14 +38,8 @@ func checkCPU(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) boo
 	ok = checkCPUArch(
 		cpuDetails.Architecture,
 		host.Status.HardwareDetails.CPU.Arch)
-	log.Info("CPU",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"architecture", cpuDetails.Architecture,
-		"actualArchitecture", host.Status.HardwareDetails.CPU.Arch,
-		"ok", ok,
-	)
+	logCheck(profile, host, "cpu-architecture",
+		cpuDetails.Architecture, host.Status.HardwareDetails.CPU.Arch, ok)
 	if !ok {
 		return false
 	}
@@ -1,6 +1,7 @@
 package classifier
 
 import (
+	"path"
 	"strconv"
 	"strings"
 
@@ -20,14 +21,9 @@ func checkDisks(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) b
 	if diskDetails.DiskSelector != nil {
 
 		filteredDisk, matched := checkDisk(diskDetails.DiskSelector, host.Status.HardwareDetails.Storage)
+		logCheck(profile, host, "disk-selector", diskDetails.DiskSelector, len(filteredDisk), matched)
 
 		if !matched {
-			log.Info("Disk Pattern",
-				"host", host.Name,
-				"profile", profile.Name,
-				"namespace", host.Namespace,
-				"ok", false,
-			)
 			return false
 		} else if len(filteredDisk) > 0 {
 			newDisk = filteredDisk
@@ -39,44 +35,32 @@ func checkDisks(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) b
 		diskDetails.MaximumCount,
 		len(newDisk),
 	)
-	log.Info("Disk Pattern",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"minCount", diskDetails.MinimumCount,
-		"maxCount", diskDetails.MaximumCount,
-		"actualCount", len(newDisk),
-		"ok", ok,
-	)
+	logCheck(profile, host, "disk-count",
+		valueRange{diskDetails.MinimumCount, diskDetails.MaximumCount},
+		len(newDisk), ok)
 	if !ok {
 		return false
 	}
 
-	for i, disk := range newDisk {
-
-		// The disk size is reported on the host in bytes and the
-		// classification rule is given in GB, so we have to convert
-		// the values to the same units. Reducing bytes to GiB loses
-		// detail, so we convert GB to bytes.
-		minSize := bmh.Capacity(diskDetails.MinimumIndividualSizeGB) * bmh.GigaByte
-		maxSize := bmh.Capacity(diskDetails.MaximumIndividualSizeGB) * bmh.GigaByte
+	// The disk size is reported on the host in bytes, the deprecated
+	// classification rule is given in GB.
+	minSize, maxSize := sizeBounds(
+		diskDetails.MinimumIndividualSize,
+		diskDetails.MaximumIndividualSize,
+		diskDetails.MinimumIndividualSizeGB,
+		diskDetails.MaximumIndividualSizeGB,
+		int64(bmh.GigaByte),
+		diskDetails.SizeTolerancePercent,
+	)
 
+	for _, disk := range newDisk {
 		ok := checkRangeCapacity(
 			minSize,
 			maxSize,
 			disk.SizeBytes,
 		)
-		log.Info("DiskSize",
-			"host", host.Name,
-			"profile", profile.Name,
-			"namespace", host.Namespace,
-			"minSize", minSize,
-			"maxSize", maxSize,
-			"actualSize", disk.SizeBytes,
-			"diskNum", i,
-			"diskName", disk.Name,
-			"ok", ok,
-		)
+		logCheck(profile, host, "disk-size",
+			valueRange{minSize, maxSize}, diskSize{disk.Name, disk.SizeBytes}, ok)
 		if !ok {
 			return false
 		}
@@ -85,6 +69,12 @@ func checkDisks(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) b
 	return true
 }
 
+// diskSize is the actual value of disk size checks in the logs.
+type diskSize struct {
+	Name string       `json:"name"`
+	Size bmh.Capacity `json:"size"`
+}
+
 // checkRangeCapacity check the range of disk count
 func checkRangeCapacity(min, max, count bmh.Capacity) bool {
 	if min > 0 && count < min {
@@ -103,29 +93,13 @@ func checkDisk(pattern []hwcc.DiskSelector, disks []bmh.Storage) ([]bmh.Storage,
 	for _, pattern := range pattern {
 		matched := false
 		for _, disk := range disks {
-			validatedPattern := validatePattern(disk.HCTL)
-			validatedExpectedPattern := validateExpectedPattern(pattern.HCTL)
-
-			if validatedExpectedPattern == validatedPattern && pattern.Rotational == disk.Rotational {
+			if diskSelected(pattern, disk) {
 				matched = true
 				diskNew = append(diskNew, disk)
-				log.Info("Disk Pattern",
-					"expectedPattern", pattern.HCTL,
-					"expectedRotational", pattern.Rotational,
-					"actualPattern", disk.HCTL,
-					"actualRotational", disk.Rotational,
-					"ok", true,
-				)
 			}
 		}
 
 		if !matched {
-			log.Info("Disk Pattern",
-				"expectedPattern", pattern.HCTL,
-				"expectedRotational", pattern.Rotational,
-				"ok", false,
-			)
-
 			return diskNew, false
 		}
 	}
@@ -133,6 +107,36 @@ func checkDisk(pattern []hwcc.DiskSelector, disks []bmh.Storage) ([]bmh.Storage,
 	return diskNew, true
 }
 
+// diskSelected reports whether the disk matches every field set in the
+// selector.
+func diskSelected(pattern hwcc.DiskSelector, disk bmh.Storage) bool {
+	if pattern.HCTL != "" && validateExpectedPattern(pattern.HCTL) != validatePattern(disk.HCTL) {
+		return false
+	}
+	if pattern.Rotational != nil && *pattern.Rotational != disk.Rotational {
+		return false
+	}
+	if pattern.Type != "" && pattern.Type != diskType(disk) {
+		return false
+	}
+	return true
+}
+
+// diskType derives the type of the disk. Ironic only reports whether
+// the disk is rotational, NVMe disks are recognised by their device
+// name or model.
+func diskType(disk bmh.Storage) hwcc.DiskType {
+	switch {
+	case disk.Rotational:
+		return hwcc.DiskTypeHDD
+	case strings.HasPrefix(path.Base(disk.Name), "nvme"),
+		strings.Contains(strings.ToLower(disk.Model), "nvme"):
+		return hwcc.DiskTypeNVMe
+	default:
+		return hwcc.DiskTypeSSD
+	}
+}
+
 // validatePattern finds out the disk with the pattern provided in hardware profile
 func validatePattern(HCTL string) string {
 
@@ -160,6 +160,48 @@ func TestCheckDiskSize(t *testing.T) {
 			Actual:   20 * bmh.GibiByte,
 			Expected: false,
 		},
+		{
+			Scenario: "legacy-decimal",
+			Rule: &hwcc.Disk{
+				MaximumIndividualSizeGB: 21,
+			},
+			Actual:   20 * bmh.GibiByte,
+			Expected: false,
+		},
+		{
+			Scenario: "quantity-within",
+			Rule: &hwcc.Disk{
+				MinimumIndividualSize: resourceQuantity("1.92T"),
+				MaximumIndividualSize: resourceQuantity("1.92T"),
+			},
+			Actual:   1920 * bmh.GigaByte,
+			Expected: true,
+		},
+		{
+			Scenario: "quantity-over-max",
+			Rule: &hwcc.Disk{
+				MaximumIndividualSize: resourceQuantity("1.92T"),
+			},
+			Actual:   2 * bmh.TeraByte,
+			Expected: false,
+		},
+		{
+			Scenario: "binary-quantity",
+			Rule: &hwcc.Disk{
+				MinimumIndividualSize: resourceQuantity("20Gi"),
+			},
+			Actual:   20 * bmh.GigaByte,
+			Expected: false,
+		},
+		{
+			Scenario: "within-tolerance",
+			Rule: &hwcc.Disk{
+				MinimumIndividualSize: resourceQuantity("1.92T"),
+				SizeTolerancePercent:  5,
+			},
+			Actual:   1860 * bmh.GigaByte,
+			Expected: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.Scenario, func(t *testing.T) {
@@ -189,6 +231,7 @@ func TestCheckDiskSize(t *testing.T) {
 }
 
 func TestCheckDiskPattern(t *testing.T) {
+	rotational := true
 	testCases := []struct {
 		Scenario string
 		Rule     *hwcc.Disk
@@ -209,7 +252,7 @@ func TestCheckDiskPattern(t *testing.T) {
 				DiskSelector: []hwcc.DiskSelector{
 					{
 						HCTL:       "N:0:0:0",
-						Rotational: true,
+						Rotational: &rotational,
 					},
 				},
 			},
@@ -222,7 +265,7 @@ func TestCheckDiskPattern(t *testing.T) {
 				MaximumCount: 4,
 				DiskSelector: []hwcc.DiskSelector{{
 					HCTL:       "0:0:N:0",
-					Rotational: true,
+					Rotational: &rotational,
 				},
 				},
 			},
@@ -281,3 +324,78 @@ func TestCheckDiskPattern(t *testing.T) {
 	}
 
 }
+
+func TestDiskSelected(t *testing.T) {
+	rotational := true
+	notRotational := false
+	hdd := bmh.Storage{Name: "/dev/sda", Rotational: true, HCTL: "0:0:0:0"}
+	ssd := bmh.Storage{Name: "/dev/sdb", HCTL: "0:0:1:0"}
+	nvme := bmh.Storage{Name: "/dev/nvme0n1", Model: "Dell Express Flash"}
+
+	testCases := []struct {
+		Scenario string
+		Selector hwcc.DiskSelector
+		Disk     bmh.Storage
+		Expected bool
+	}{
+		{
+			Scenario: "empty",
+			Disk:     hdd,
+			Expected: true,
+		},
+		{
+			Scenario: "rotational unset",
+			Selector: hwcc.DiskSelector{HCTL: "0:0:N:0"},
+			Disk:     ssd,
+			Expected: true,
+		},
+		{
+			Scenario: "rotational false",
+			Selector: hwcc.DiskSelector{Rotational: &notRotational},
+			Disk:     hdd,
+			Expected: false,
+		},
+		{
+			Scenario: "rotational true",
+			Selector: hwcc.DiskSelector{Rotational: &rotational},
+			Disk:     hdd,
+			Expected: true,
+		},
+		{
+			Scenario: "hctl mismatch",
+			Selector: hwcc.DiskSelector{HCTL: "N:0:0:0"},
+			Disk:     ssd,
+			Expected: false,
+		},
+		{
+			Scenario: "type ssd",
+			Selector: hwcc.DiskSelector{Type: hwcc.DiskTypeSSD},
+			Disk:     ssd,
+			Expected: true,
+		},
+		{
+			Scenario: "type ssd not nvme",
+			Selector: hwcc.DiskSelector{Type: hwcc.DiskTypeSSD},
+			Disk:     nvme,
+			Expected: false,
+		},
+		{
+			Scenario: "type nvme",
+			Selector: hwcc.DiskSelector{Type: hwcc.DiskTypeNVMe, Rotational: &notRotational},
+			Disk:     nvme,
+			Expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, diskSelected(tc.Selector, tc.Disk))
+		})
+	}
+}
+
+func TestDiskType(t *testing.T) {
+	assert.Equal(t, hwcc.DiskTypeHDD, diskType(bmh.Storage{Name: "/dev/sda", Rotational: true}))
+	assert.Equal(t, hwcc.DiskTypeSSD, diskType(bmh.Storage{Name: "/dev/sda"}))
+	assert.Equal(t, hwcc.DiskTypeNVMe, diskType(bmh.Storage{Name: "/dev/nvme1n1"}))
+	assert.Equal(t, hwcc.DiskTypeNVMe, diskType(bmh.Storage{Name: "disk 1", Model: "Samsung NVMe SSD"}))
+}
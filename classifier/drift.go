@@ -0,0 +1,87 @@
+package classifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// FactChange describes how a single hardware fact of a host changed
+// between two inspections.
+type FactChange struct {
+	Fact string `json:"fact"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// HardwareHashPrefix starts the digests returned by HardwareHash.
+// Digests without it were computed from all the hardware details, they
+// cannot be compared with the current ones.
+const HardwareHashPrefix = "v2:"
+
+// hashedHardware is the hardware of a host whose changes are reported
+// as a drift: the parts which are only replaced, not the values which
+// change without any hardware change, such as the hostname, the NIC
+// addresses and link speeds, the CPU clock or the firmware versions.
+type hashedHardware struct {
+	Manufacturer string               `json:"manufacturer"`
+	ProductName  string               `json:"productName"`
+	CPU          hwcc.CPUSignature    `json:"cpu"`
+	RAMMebibytes int                  `json:"ramMebibytes"`
+	Disks        []hwcc.DiskSignature `json:"disks"`
+	// NICs counts the network interfaces by model.
+	NICs map[string]int `json:"nics"`
+}
+
+// HardwareHash returns a digest of the hardware of the host, or an
+// empty string when the host has not been inspected. The hardware is
+// described as in HostHardwareSignature, without the NIC speeds.
+func HardwareHash(host *bmh.BareMetalHost) string {
+	signature := HostHardwareSignature(host)
+	if signature == nil {
+		return ""
+	}
+
+	hardware := hashedHardware{
+		Manufacturer: signature.Manufacturer,
+		ProductName:  signature.ProductName,
+		CPU:          signature.CPU,
+		RAMMebibytes: signature.RAMMebibytes,
+		Disks:        signature.Disks,
+		NICs:         map[string]int{},
+	}
+	for _, nic := range signature.NICs {
+		hardware.NICs[nic.Model] += nic.Count
+	}
+
+	data, err := json.Marshal(hardware)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return HardwareHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// DiffFacts returns the facts whose value differs between the two
+// sets, ordered by fact name.
+func DiffFacts(oldFacts, newFacts map[string]string) []FactChange {
+	changes := []FactChange{}
+	for _, name := range FactNames {
+		if oldFacts[name] != newFacts[name] {
+			changes = append(changes, FactChange{
+				Fact: name,
+				Old:  oldFacts[name],
+				New:  newFacts[name],
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Fact < changes[j].Fact
+	})
+	return changes
+}
@@ -0,0 +1,81 @@
+package classifier
+
+import (
+	"strings"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHardwareHash(t *testing.T) {
+	newHost := func() *bmh.BareMetalHost {
+		return &bmh.BareMetalHost{
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					Hostname:     "node-0",
+					RAMMebibytes: 65536,
+					NIC:          []bmh.NIC{{Name: "eth0", IP: "192.168.0.10"}},
+					Storage:      []bmh.Storage{{Name: "sda"}, {Name: "sdb"}},
+				},
+			},
+		}
+	}
+
+	assert.Empty(t, HardwareHash(&bmh.BareMetalHost{}))
+
+	original := HardwareHash(newHost())
+	assert.NotEmpty(t, original)
+	assert.Equal(t, original, HardwareHash(newHost()))
+
+	assert.True(t, strings.HasPrefix(original, HardwareHashPrefix))
+
+	renamed := newHost()
+	renamed.Status.HardwareDetails.Hostname = "node-1"
+	renamed.Status.HardwareDetails.NIC[0].IP = "192.168.0.11"
+	assert.Equal(t, original, HardwareHash(renamed), "hostname and IP are ignored")
+
+	reinspected := newHost()
+	reinspected.Status.HardwareDetails.CPU.ClockMegahertz = 3600
+	reinspected.Status.HardwareDetails.NIC[0].SpeedGbps = 10
+	reinspected.Status.HardwareDetails.Firmware.BIOS.Version = "2.0"
+	reinspected.Status.HardwareDetails.Storage[0], reinspected.Status.HardwareDetails.Storage[1] =
+		reinspected.Status.HardwareDetails.Storage[1], reinspected.Status.HardwareDetails.Storage[0]
+	assert.Equal(t, original, HardwareHash(reinspected),
+		"clock, link speed, firmware and device order are ignored")
+
+	lostDisk := newHost()
+	lostDisk.Status.HardwareDetails.Storage = lostDisk.Status.HardwareDetails.Storage[:1]
+	assert.NotEqual(t, original, HardwareHash(lostDisk))
+
+	lostDIMM := newHost()
+	lostDIMM.Status.HardwareDetails.RAMMebibytes = 49152
+	assert.NotEqual(t, original, HardwareHash(lostDIMM))
+
+	replacedNIC := newHost()
+	replacedNIC.Status.HardwareDetails.NIC[0].Model = "0x8086 0x1572"
+	assert.NotEqual(t, original, HardwareHash(replacedNIC))
+
+	replacedCPU := newHost()
+	replacedCPU.Status.HardwareDetails.CPU.Model = "Intel Xeon Gold 6230"
+	assert.NotEqual(t, original, HardwareHash(replacedCPU))
+}
+
+func TestDiffFacts(t *testing.T) {
+	old := map[string]string{
+		FactCPUCount:  "16",
+		FactRAMGB:     "64",
+		FactDiskCount: "2",
+	}
+	newFacts := map[string]string{
+		FactCPUCount:  "16",
+		FactRAMGB:     "48",
+		FactDiskCount: "1",
+	}
+
+	assert.Equal(t, []FactChange{
+		{Fact: FactDiskCount, Old: "2", New: "1"},
+		{Fact: FactRAMGB, Old: "64", New: "48"},
+	}, DiffFacts(old, newFacts))
+	assert.Empty(t, DiffFacts(old, old))
+}
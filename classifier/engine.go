@@ -0,0 +1,134 @@
+package classifier
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// DefaultEngine classifies with one worker per CPU, using the default
+// cache.
+var DefaultEngine = NewEngine(0, DefaultCache)
+
+// Engine classifies many hosts against many profiles, sharing the work
+// between a bounded number of workers.
+type Engine struct {
+	workers int
+	cache   *Cache
+}
+
+// Result is the classification of a host against a profile.
+type Result struct {
+	Host *bmh.BareMetalHost
+	// Matches reports whether the host matches the profile. Hosts
+	// which have not been inspected never match.
+	Matches bool
+	// Score is the fit score of the host. It is only computed for
+	// profiles enabling scoring or selecting the best scoring hosts.
+	Score int
+}
+
+// ProfileResults holds the classification of the hosts against a
+// profile, in the order the hosts were given.
+type ProfileResults struct {
+	Profile *hwcc.HardwareClassification
+	Hosts   []Result
+}
+
+// Matching returns the results of the hosts matching the profile.
+func (r *ProfileResults) Matching() []Result {
+	matching := []Result{}
+	for _, result := range r.Hosts {
+		if result.Matches {
+			matching = append(matching, result)
+		}
+	}
+	return matching
+}
+
+// NewEngine returns an engine running at most workers classifications
+// in parallel, or one per CPU when workers is not positive. Results are
+// looked up in and stored to the cache unless it is nil.
+func NewEngine(workers int, cache *Cache) *Engine {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &Engine{workers: workers, cache: cache}
+}
+
+// Classify classifies every host against every profile and returns the
+// results in the order of the profiles. It stops and returns the error
+// of the context when the context is done.
+func (e *Engine) Classify(ctx context.Context, profiles []hwcc.HardwareClassification, hosts []bmh.BareMetalHost) ([]ProfileResults, error) {
+	results := make([]ProfileResults, len(profiles))
+	for i := range profiles {
+		results[i] = ProfileResults{
+			Profile: &profiles[i],
+			Hosts:   make([]Result, len(hosts)),
+		}
+	}
+
+	workers := e.workers
+	if workers > len(hosts) {
+		workers = len(hosts)
+	}
+
+	// Each worker classifies whole hosts, so every result is written
+	// by a single worker and the hardware hash of a host is only
+	// computed once.
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range jobs {
+				for p := range profiles {
+					results[p].Hosts[h] = e.classify(&profiles[p], &hosts[h])
+				}
+			}
+		}()
+	}
+
+	err := ctx.Err()
+	for h := 0; h < len(hosts) && err == nil; h++ {
+		select {
+		case jobs <- h:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (e *Engine) classify(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) Result {
+	result := Result{Host: host}
+	if host.Status.HardwareDetails == nil {
+		return result
+	}
+
+	if e.cache != nil {
+		result.Matches = e.cache.ProfileMatchesHost(profile, host)
+	} else {
+		result.Matches = ProfileMatchesHost(profile, host)
+	}
+	if profile.Spec.Scoring == nil && profile.Spec.SelectionPolicy != hwcc.SelectionPolicyBestScore {
+		return result
+	}
+	if e.cache != nil {
+		result.Score = e.cache.ProfileScoreForHost(profile, host)
+	} else {
+		result.Score = ProfileScoreForHost(profile, host)
+	}
+	return result
+}
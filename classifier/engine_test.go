@@ -0,0 +1,206 @@
+package classifier
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// syntheticFleet returns hosts and profiles with varied hardware and
+// rules, the same ones for the same sizes.
+func syntheticFleet(hostCount, profileCount int) ([]hwcc.HardwareClassification, []bmh.BareMetalHost) {
+	random := rand.New(rand.NewSource(int64(hostCount*1000 + profileCount)))
+
+	hosts := make([]bmh.BareMetalHost, hostCount)
+	for i := range hosts {
+		nics := make([]bmh.NIC, 1+random.Intn(4))
+		for j := range nics {
+			nics[j] = bmh.NIC{Name: fmt.Sprintf("eth%d", j), Model: "0x8086 0x1572", SpeedGbps: 10}
+		}
+		disks := make([]bmh.Storage, 1+random.Intn(6))
+		for j := range disks {
+			disks[j] = bmh.Storage{
+				Name:       fmt.Sprintf("/dev/sd%c", 'a'+j),
+				SizeBytes:  bmh.Capacity(1+random.Intn(4)) * 500 * bmh.GigaByte,
+				Rotational: random.Intn(2) == 0,
+			}
+		}
+		hosts[i] = bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            fmt.Sprintf("host-%d", i),
+				UID:             types.UID(fmt.Sprintf("host-%d", i)),
+				ResourceVersion: "1",
+			},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{
+					CPU: bmh.CPU{
+						Arch:           "x86_64",
+						Count:          8 << random.Intn(4),
+						ClockMegahertz: bmh.ClockSpeed(2000 + 200*random.Intn(8)),
+					},
+					RAMMebibytes: (16 << random.Intn(5)) * 1024,
+					NIC:          nics,
+					Storage:      disks,
+				},
+			},
+		}
+	}
+
+	profiles := make([]hwcc.HardwareClassification, profileCount)
+	for i := range profiles {
+		profiles[i] = hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       fmt.Sprintf("profile-%d", i),
+				UID:        types.UID(fmt.Sprintf("profile-%d", i)),
+				Generation: 1,
+			},
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu:  &hwcc.Cpu{MinimumCount: 8 << random.Intn(4)},
+					Ram:  &hwcc.Ram{MinimumSizeGB: 16 << random.Intn(5)},
+					Nic:  &hwcc.Nic{MinimumCount: 1 + random.Intn(4)},
+					Disk: &hwcc.Disk{MinimumCount: 1 + random.Intn(6)},
+				},
+			},
+		}
+		if i%4 == 0 {
+			profiles[i].Spec.Scoring = &hwcc.Scoring{MinimumScore: 50}
+		}
+	}
+	return profiles, hosts
+}
+
+func TestEngineClassify(t *testing.T) {
+	profiles, hosts := syntheticFleet(50, 8)
+	hosts = append(hosts, bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "not-inspected"},
+	})
+
+	testCases := []struct {
+		Scenario string
+		Workers  int
+		Cache    *Cache
+	}{
+		{
+			Scenario: "one-worker",
+			Workers:  1,
+		},
+		{
+			Scenario: "default-workers",
+		},
+		{
+			Scenario: "more-workers-than-hosts",
+			Workers:  100,
+		},
+		{
+			Scenario: "cache",
+			Workers:  4,
+			Cache:    NewCache(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			engine := NewEngine(tc.Workers, tc.Cache)
+			results, err := engine.Classify(context.TODO(), profiles, hosts)
+			assert.NoError(t, err)
+			assert.Len(t, results, len(profiles))
+
+			for p := range profiles {
+				assert.Equal(t, profiles[p].Name, results[p].Profile.Name)
+				assert.Len(t, results[p].Hosts, len(hosts))
+				for h := range hosts {
+					result := results[p].Hosts[h]
+					assert.Equal(t, hosts[h].Name, result.Host.Name)
+					if hosts[h].Status.HardwareDetails == nil {
+						assert.False(t, result.Matches)
+						continue
+					}
+					assert.Equal(t, ProfileMatchesHost(&profiles[p], &hosts[h]), result.Matches,
+						"profile=%s host=%s", profiles[p].Name, hosts[h].Name)
+					if profiles[p].Spec.Scoring != nil {
+						assert.Equal(t, ProfileScoreForHost(&profiles[p], &hosts[h]), result.Score)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEngineClassifyCancelled(t *testing.T) {
+	profiles, hosts := syntheticFleet(50, 8)
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	results, err := NewEngine(1, nil).Classify(ctx, profiles, hosts)
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, results)
+}
+
+func TestProfileResultsMatching(t *testing.T) {
+	results := ProfileResults{
+		Hosts: []Result{
+			{Host: &bmh.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "a"}}, Matches: true},
+			{Host: &bmh.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "b"}}},
+			{Host: &bmh.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: "c"}}, Matches: true},
+		},
+	}
+	matching := results.Matching()
+	assert.Len(t, matching, 2)
+	assert.Equal(t, "a", matching[0].Host.Name)
+	assert.Equal(t, "c", matching[1].Host.Name)
+}
+
+func BenchmarkEngineClassify(b *testing.B) {
+	profiles, hosts := syntheticFleet(10000, 200)
+
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			engine := NewEngine(workers, nil)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := engine.Classify(context.TODO(), profiles, hosts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEngineClassifyCached measures classifying again a fleet
+// whose hosts and profiles did not change. The fleet is smaller than in
+// BenchmarkEngineClassify to keep the cache within memory.
+func BenchmarkEngineClassifyCached(b *testing.B) {
+	profiles, hosts := syntheticFleet(10000, 20)
+	engine := NewEngine(0, NewCache())
+	if _, err := engine.Classify(context.TODO(), profiles, hosts); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.Classify(context.TODO(), profiles, hosts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProfileMatchesHost(b *testing.B) {
+	profiles, hosts := syntheticFleet(10000, 200)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProfileMatchesHost(&profiles[i%len(profiles)], &hosts[i%len(hosts)])
+	}
+}
@@ -0,0 +1,97 @@
+package classifier
+
+import (
+	"strconv"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+)
+
+// FactLabelPrefix is the prefix used for the labels describing the
+// hardware of a host, independent of any classification profile.
+const FactLabelPrefix = "hwcc.metal3.io/"
+
+// Names of the hardware facts derived from the host inspection data.
+const (
+	FactCPUArch     = "cpu-arch"
+	FactCPUCount    = "cpu-count"
+	FactRAMGB       = "ram-gb"
+	FactNICCount    = "nic-count"
+	FactDiskCount   = "disk-count"
+	FactVendor      = "vendor"
+	FactProduct     = "product"
+	FactBIOSVersion = "bios-version"
+)
+
+// FactNames lists all of the facts computed by HostFacts.
+var FactNames = []string{
+	FactCPUArch,
+	FactCPUCount,
+	FactRAMGB,
+	FactNICCount,
+	FactDiskCount,
+	FactVendor,
+	FactProduct,
+	FactBIOSVersion,
+}
+
+// maxLabelValueLength is the longest value allowed for a label.
+const maxLabelValueLength = 63
+
+// HostFacts returns the normalised hardware facts of the host, keyed
+// by fact name. The values are sanitised so they can be used as label
+// values. Facts that cannot be determined have an empty value.
+func HostFacts(host *bmh.BareMetalHost) map[string]string {
+	facts := make(map[string]string, len(FactNames))
+	for _, name := range FactNames {
+		facts[name] = ""
+	}
+
+	details := host.Status.HardwareDetails
+	if details == nil {
+		return facts
+	}
+
+	facts[FactCPUArch] = SanitizeLabelValue(details.CPU.Arch)
+	facts[FactCPUCount] = strconv.Itoa(details.CPU.Count)
+	// The RAM size is reported in MiB, but the label is meant to be
+	// read by humans so we round down to whole GiB.
+	facts[FactRAMGB] = strconv.Itoa(details.RAMMebibytes / 1024)
+	facts[FactNICCount] = strconv.Itoa(len(details.NIC))
+	facts[FactDiskCount] = strconv.Itoa(len(details.Storage))
+	facts[FactVendor] = SanitizeLabelValue(details.SystemVendor.Manufacturer)
+	facts[FactProduct] = SanitizeLabelValue(details.SystemVendor.ProductName)
+	facts[FactBIOSVersion] = SanitizeLabelValue(details.Firmware.BIOS.Version)
+	return facts
+}
+
+// SanitizeLabelValue converts an arbitrary string to a valid label
+// value by replacing unsupported characters with "-", trimming
+// leading and trailing non-alphanumeric characters, and truncating
+// the result to the maximum label value length.
+func SanitizeLabelValue(value string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case isAlphaNumeric(r), r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.TrimSpace(value))
+
+	sanitized = trimNonAlphaNumeric(sanitized)
+	if len(sanitized) > maxLabelValueLength {
+		sanitized = trimNonAlphaNumeric(sanitized[:maxLabelValueLength])
+	}
+	return sanitized
+}
+
+func trimNonAlphaNumeric(value string) string {
+	return strings.TrimFunc(value, func(r rune) bool {
+		return !isAlphaNumeric(r)
+	})
+}
+
+func isAlphaNumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
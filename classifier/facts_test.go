@@ -0,0 +1,99 @@
+package classifier
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Value    string
+		Expected string
+	}{
+		{
+			Scenario: "empty",
+			Value:    "",
+			Expected: "",
+		},
+		{
+			Scenario: "already-valid",
+			Value:    "x86_64",
+			Expected: "x86_64",
+		},
+		{
+			Scenario: "spaces",
+			Value:    "Dell Inc.",
+			Expected: "Dell-Inc",
+		},
+		{
+			Scenario: "parentheses",
+			Value:    "Standard PC (Q35 + ICH9, 2009)",
+			Expected: "Standard-PC--Q35---ICH9--2009",
+		},
+		{
+			Scenario: "leading-trailing-punctuation",
+			Value:    " -1.2.3- ",
+			Expected: "1.2.3",
+		},
+		{
+			Scenario: "too-long",
+			Value:    "0123456789012345678901234567890123456789012345678901234567890123456789",
+			Expected: "012345678901234567890123456789012345678901234567890123456789012",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			actual := SanitizeLabelValue(tc.Value)
+			assert.Equal(t, tc.Expected, actual)
+			assert.Empty(t, validation.IsValidLabelValue(actual))
+		})
+	}
+}
+
+func TestHostFacts(t *testing.T) {
+	host := bmh.BareMetalHost{
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				SystemVendor: bmh.HardwareSystemVendor{
+					Manufacturer: "Dell Inc.",
+					ProductName:  "PowerEdge R640 (SKU=NotProvided)",
+				},
+				Firmware: bmh.Firmware{
+					BIOS: bmh.BIOS{Version: "2.4.8"},
+				},
+				RAMMebibytes: 393216,
+				NIC:          []bmh.NIC{{Name: "eth0"}, {Name: "eth1"}},
+				Storage:      []bmh.Storage{{Name: "sda"}, {Name: "sdb"}, {Name: "sdc"}},
+				CPU: bmh.CPU{
+					Arch:  "x86_64",
+					Count: 48,
+				},
+			},
+		},
+	}
+
+	expected := map[string]string{
+		FactCPUArch:     "x86_64",
+		FactCPUCount:    "48",
+		FactRAMGB:       "384",
+		FactNICCount:    "2",
+		FactDiskCount:   "3",
+		FactVendor:      "Dell-Inc",
+		FactProduct:     "PowerEdge-R640--SKU-NotProvided",
+		FactBIOSVersion: "2.4.8",
+	}
+	assert.Equal(t, expected, HostFacts(&host))
+}
+
+func TestHostFactsNoDetails(t *testing.T) {
+	facts := HostFacts(&bmh.BareMetalHost{})
+	assert.Len(t, facts, len(FactNames))
+	for name, value := range facts {
+		assert.Empty(t, value, name)
+	}
+}
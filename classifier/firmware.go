@@ -16,15 +16,8 @@ func checkFirmware(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost
 	}
 
 	ok := checkString(firmwareDetails.BIOS.Vendor, host.Status.HardwareDetails.Firmware.BIOS.Vendor)
-
-	log.Info("Firmware",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"vendor", firmwareDetails.BIOS.Vendor,
-		"actualVendor", host.Status.HardwareDetails.Firmware.BIOS.Vendor,
-		"ok", ok,
-	)
+	logCheck(profile, host, "firmware-vendor",
+		firmwareDetails.BIOS.Vendor, host.Status.HardwareDetails.Firmware.BIOS.Vendor, ok)
 	if !ok {
 		return false
 	}
@@ -32,16 +25,9 @@ func checkFirmware(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost
 	ok = checkVersion(firmwareDetails.BIOS.MinorVersion,
 		firmwareDetails.BIOS.MajorVersion,
 		host.Status.HardwareDetails.Firmware.BIOS.Version)
-
-	log.Info("Firmware",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"minor version", firmwareDetails.BIOS.MinorVersion,
-		"major version", firmwareDetails.BIOS.MajorVersion,
-		"actualVersion", host.Status.HardwareDetails.Firmware.BIOS.Version,
-		"ok", ok,
-	)
+	logCheck(profile, host, "firmware-version",
+		valueRange{firmwareDetails.BIOS.MinorVersion, firmwareDetails.BIOS.MajorVersion},
+		host.Status.HardwareDetails.Firmware.BIOS.Version, ok)
 	if !ok {
 		return false
 	}
@@ -0,0 +1,127 @@
+package classifier
+
+import (
+	"sort"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// cpuArchitectures are the architectures accepted in profiles.
+var cpuArchitectures = map[string]bool{"x86": true, "x86_64": true, "IAS": true, "AMD64": true}
+
+// SignatureCharacteristics returns the characteristics matching the
+// hosts with the given hardware signature. Counts may differ by up to
+// slackPercent of the value of the signature and sizes by up to
+// slackPercent, so a zero slack only matches hosts with the same
+// counts and sizes.
+func SignatureCharacteristics(signature hwcc.HardwareSignature, slackPercent int) hwcc.HardwareCharacteristics {
+	characteristics := hwcc.HardwareCharacteristics{}
+
+	if signature.Manufacturer != "" || signature.ProductName != "" {
+		characteristics.SystemVendor = &hwcc.SystemVendor{
+			Manufacturer: signature.Manufacturer,
+			ProductName:  signature.ProductName,
+		}
+	}
+
+	if signature.CPU.Count > 0 {
+		characteristics.Cpu = &hwcc.Cpu{}
+		characteristics.Cpu.MinimumCount, characteristics.Cpu.MaximumCount =
+			countRange(signature.CPU.Count, slackPercent)
+		// Architectures outside of the enum of the API are left out
+		// so the profile can be created.
+		if cpuArchitectures[signature.CPU.Arch] {
+			characteristics.Cpu.Architecture = signature.CPU.Arch
+		}
+	}
+
+	if signature.RAMMebibytes > 0 {
+		size := int64(signature.RAMMebibytes) * int64(bmh.MebiByte)
+		characteristics.Ram = &hwcc.Ram{
+			MinimumSize:          resource.NewQuantity(size, resource.BinarySI),
+			MaximumSize:          resource.NewQuantity(size, resource.BinarySI),
+			SizeTolerancePercent: slackPercent,
+		}
+	}
+
+	if len(signature.Disks) > 0 {
+		characteristics.Disk = signatureDisk(signature.Disks, slackPercent)
+	}
+
+	if len(signature.NICs) > 0 {
+		characteristics.Nic = signatureNic(signature.NICs, slackPercent)
+	}
+
+	return characteristics
+}
+
+// signatureDisk selects the disks of every type of the signature, so
+// hosts missing one of the types do not match. A disk rule only has one
+// count and size range for all the selected disks, so hosts with the
+// same disk types in other numbers or sizes, within those ranges, match
+// as well.
+func signatureDisk(disks []hwcc.DiskSignature, slackPercent int) *hwcc.Disk {
+	count := 0
+	minSize, maxSize := disks[0].SizeBytes, disks[0].SizeBytes
+	types := []hwcc.DiskType{}
+	for _, disk := range disks {
+		count += disk.Count
+		if disk.SizeBytes < minSize {
+			minSize = disk.SizeBytes
+		}
+		if disk.SizeBytes > maxSize {
+			maxSize = disk.SizeBytes
+		}
+		if len(types) == 0 || types[len(types)-1] != disk.Type {
+			types = append(types, disk.Type)
+		}
+	}
+
+	rule := &hwcc.Disk{
+		MinimumIndividualSize: resource.NewQuantity(minSize, resource.DecimalSI),
+		MaximumIndividualSize: resource.NewQuantity(maxSize, resource.DecimalSI),
+		SizeTolerancePercent:  slackPercent,
+	}
+	rule.MinimumCount, rule.MaximumCount = countRange(count, slackPercent)
+	// The disk signatures are sorted by type, so each type is only
+	// selected once.
+	for _, diskType := range types {
+		rule.DiskSelector = append(rule.DiskSelector, hwcc.DiskSelector{Type: diskType})
+	}
+	return rule
+}
+
+// signatureNic requires the vendors of the NICs of the signature.
+func signatureNic(nics []hwcc.NICSignature, slackPercent int) *hwcc.Nic {
+	count := 0
+	vendors := map[string]bool{}
+	for _, nic := range nics {
+		count += nic.Count
+		if fields := strings.Fields(nic.Model); len(fields) > 0 {
+			vendors[fields[0]] = true
+		}
+	}
+
+	rule := &hwcc.Nic{}
+	rule.MinimumCount, rule.MaximumCount = countRange(count, slackPercent)
+	for vendor := range vendors {
+		rule.NicSelector.Vendor = append(rule.NicSelector.Vendor, vendor)
+	}
+	sort.Strings(rule.NicSelector.Vendor)
+	return rule
+}
+
+// countRange returns the range of counts within slackPercent of count,
+// keeping the minimum positive as zero means unbounded.
+func countRange(count, slackPercent int) (int, int) {
+	slack := count * slackPercent / 100
+	min := count - slack
+	if min < 1 {
+		min = 1
+	}
+	return min, count + slack
+}
@@ -0,0 +1,111 @@
+package classifier
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestSignatureCharacteristics(t *testing.T) {
+	nics := []bmh.NIC{
+		{Name: "eno1", Model: "0x8086 0x1572", SpeedGbps: 10},
+		{Name: "eno2", Model: "0x8086 0x1572", SpeedGbps: 10},
+		{Name: "eno3", Model: "0x14e4 0x165f", SpeedGbps: 1},
+	}
+	disks := []bmh.Storage{
+		{Name: "/dev/sda", SizeBytes: 480 * bmh.GigaByte},
+		{Name: "/dev/sdb", SizeBytes: 1920 * bmh.GigaByte, Rotational: true},
+	}
+	profileHost := inventoryTestHost("host-0", 40, nics, disks)
+
+	testCases := []struct {
+		Scenario string
+		Slack    int
+		Host     bmh.BareMetalHost
+		Matches  bool
+	}{
+		{
+			Scenario: "same-hardware",
+			Host:     inventoryTestHost("host-1", 40, nics, disks),
+			Matches:  true,
+		},
+		{
+			Scenario: "more-cpus",
+			Host:     inventoryTestHost("host-1", 44, nics, disks),
+			Matches:  false,
+		},
+		{
+			Scenario: "more-cpus-within-slack",
+			Slack:    10,
+			Host:     inventoryTestHost("host-1", 44, nics, disks),
+			Matches:  true,
+		},
+		{
+			Scenario: "fewer-cpus-outside-slack",
+			Slack:    10,
+			Host:     inventoryTestHost("host-1", 32, nics, disks),
+			Matches:  false,
+		},
+		{
+			Scenario: "missing-nic",
+			Host:     inventoryTestHost("host-1", 40, nics[:2], disks),
+			Matches:  false,
+		},
+		{
+			Scenario: "missing-disk-type",
+			Host: inventoryTestHost("host-1", 40, nics, []bmh.Storage{
+				{Name: "/dev/sda", SizeBytes: 480 * bmh.GigaByte},
+				{Name: "/dev/sdb", SizeBytes: 1920 * bmh.GigaByte},
+			}),
+			Matches: false,
+		},
+		{
+			Scenario: "smaller-disk-within-slack",
+			Slack:    5,
+			Host: inventoryTestHost("host-1", 40, nics, []bmh.Storage{
+				{Name: "/dev/sda", SizeBytes: 470 * bmh.GigaByte},
+				{Name: "/dev/sdb", SizeBytes: 1920 * bmh.GigaByte, Rotational: true},
+			}),
+			Matches: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := &hwcc.HardwareClassification{
+				Spec: hwcc.HardwareClassificationSpec{
+					HardwareCharacteristics: SignatureCharacteristics(*HostHardwareSignature(&profileHost), tc.Slack),
+				},
+			}
+			assert.True(t, ProfileMatchesHost(profile, &profileHost))
+			assert.Equal(t, tc.Matches, ProfileMatchesHost(profile, &tc.Host))
+		})
+	}
+}
+
+func TestSignatureCharacteristicsRules(t *testing.T) {
+	signature := hwcc.HardwareSignature{
+		Manufacturer: "Dell Inc.",
+		ProductName:  "PowerEdge R640",
+		CPU:          hwcc.CPUSignature{Arch: "aarch64", Count: 64},
+		RAMMebibytes: 262144,
+		NICs:         []hwcc.NICSignature{{Model: "0x8086 0x1572", SpeedGbps: 10, Count: 2}},
+	}
+	characteristics := SignatureCharacteristics(signature, 10)
+
+	assert.Equal(t, &hwcc.SystemVendor{Manufacturer: "Dell Inc.", ProductName: "PowerEdge R640"},
+		characteristics.SystemVendor)
+	assert.Equal(t, &hwcc.Cpu{MinimumCount: 58, MaximumCount: 70}, characteristics.Cpu)
+	assert.Equal(t, "256Gi", characteristics.Ram.MinimumSize.String())
+	assert.Equal(t, "256Gi", characteristics.Ram.MaximumSize.String())
+	assert.Equal(t, 10, characteristics.Ram.SizeTolerancePercent)
+	assert.Nil(t, characteristics.Disk)
+	assert.Equal(t, &hwcc.Nic{
+		NicSelector:  hwcc.NicSelector{Vendor: []string{"0x8086"}},
+		MinimumCount: 2,
+		MaximumCount: 2,
+	}, characteristics.Nic)
+}
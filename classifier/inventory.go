@@ -0,0 +1,106 @@
+package classifier
+
+import (
+	"encoding/json"
+	"sort"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// HostHardwareSignature returns the hardware of the host compared when
+// grouping hosts, or nil when the host has not been inspected. Disks
+// and NICs are counted by type and size, and model and speed, so hosts
+// whose devices were enumerated in another order have the same
+// signature.
+func HostHardwareSignature(host *bmh.BareMetalHost) *hwcc.HardwareSignature {
+	details := host.Status.HardwareDetails
+	if details == nil {
+		return nil
+	}
+
+	signature := &hwcc.HardwareSignature{
+		Manufacturer: details.SystemVendor.Manufacturer,
+		ProductName:  details.SystemVendor.ProductName,
+		CPU: hwcc.CPUSignature{
+			Arch:  details.CPU.Arch,
+			Model: details.CPU.Model,
+			Count: details.CPU.Count,
+		},
+		RAMMebibytes: details.RAMMebibytes,
+	}
+
+	disks := map[hwcc.DiskSignature]int{}
+	for _, disk := range details.Storage {
+		disks[hwcc.DiskSignature{Type: diskType(disk), SizeBytes: int64(disk.SizeBytes)}]++
+	}
+	for disk, count := range disks {
+		disk.Count = count
+		signature.Disks = append(signature.Disks, disk)
+	}
+	sort.Slice(signature.Disks, func(i, j int) bool {
+		a, b := signature.Disks[i], signature.Disks[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.SizeBytes < b.SizeBytes
+	})
+
+	nics := map[hwcc.NICSignature]int{}
+	for _, nic := range details.NIC {
+		nics[hwcc.NICSignature{Model: nic.Model, SpeedGbps: nic.SpeedGbps}]++
+	}
+	for nic, count := range nics {
+		nic.Count = count
+		signature.NICs = append(signature.NICs, nic)
+	}
+	sort.Slice(signature.NICs, func(i, j int) bool {
+		a, b := signature.NICs[i], signature.NICs[j]
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.SpeedGbps < b.SpeedGbps
+	})
+
+	return signature
+}
+
+// GroupHosts groups the hosts with the same hardware signature and
+// returns the groups, the largest first, and the number of hosts which
+// have not been inspected and are in no group.
+func GroupHosts(hosts []bmh.BareMetalHost) (groups []hwcc.HardwareGroup, uninspected int) {
+	byKey := map[string]int{}
+	for i := range hosts {
+		signature := HostHardwareSignature(&hosts[i])
+		if signature == nil {
+			uninspected++
+			continue
+		}
+		// The signature only holds strings, numbers and slices, so
+		// its encoding is stable.
+		encoded, _ := json.Marshal(signature)
+		key := string(encoded)
+		g, ok := byKey[key]
+		if !ok {
+			g = len(groups)
+			byKey[key] = g
+			groups = append(groups, hwcc.HardwareGroup{Hardware: *signature})
+		}
+		groups[g].Hosts = append(groups[g].Hosts, hosts[i].Name)
+		groups[g].Count++
+	}
+
+	for g := range groups {
+		sort.Strings(groups[g].Hosts)
+	}
+	// Groups of the same size are ordered by their first host, so the
+	// order does not depend on the order of the hosts.
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Hosts[0] < groups[j].Hosts[0]
+	})
+	return groups, uninspected
+}
@@ -0,0 +1,137 @@
+package classifier
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func inventoryTestHost(name string, cpuCount int, nics []bmh.NIC, disks []bmh.Storage) bmh.BareMetalHost {
+	return bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				SystemVendor: bmh.HardwareSystemVendor{
+					Manufacturer: "Dell Inc.",
+					ProductName:  "PowerEdge R640",
+					SerialNumber: name,
+				},
+				CPU:          bmh.CPU{Arch: "x86_64", Model: "Intel Xeon Gold 6230", Count: cpuCount},
+				RAMMebibytes: 196608,
+				NIC:          nics,
+				Storage:      disks,
+				Hostname:     name,
+			},
+		},
+	}
+}
+
+func TestHostHardwareSignature(t *testing.T) {
+	host := inventoryTestHost("host-0", 80,
+		[]bmh.NIC{
+			{Name: "eno1", Model: "0x8086 0x1572", SpeedGbps: 10, MAC: "00:00:00:00:00:01"},
+			{Name: "eno2", Model: "0x8086 0x1572", SpeedGbps: 10, MAC: "00:00:00:00:00:02"},
+			{Name: "eno3", Model: "0x8086 0x1521", SpeedGbps: 1, MAC: "00:00:00:00:00:03"},
+		},
+		[]bmh.Storage{
+			{Name: "/dev/sdb", SizeBytes: 1920 * bmh.GigaByte, Rotational: true},
+			{Name: "/dev/nvme0n1", SizeBytes: 960 * bmh.GigaByte},
+			{Name: "/dev/sda", SizeBytes: 1920 * bmh.GigaByte, Rotational: true},
+		})
+
+	assert.Equal(t, &hwcc.HardwareSignature{
+		Manufacturer: "Dell Inc.",
+		ProductName:  "PowerEdge R640",
+		CPU:          hwcc.CPUSignature{Arch: "x86_64", Model: "Intel Xeon Gold 6230", Count: 80},
+		RAMMebibytes: 196608,
+		Disks: []hwcc.DiskSignature{
+			{Type: hwcc.DiskTypeHDD, SizeBytes: int64(1920 * bmh.GigaByte), Count: 2},
+			{Type: hwcc.DiskTypeNVMe, SizeBytes: int64(960 * bmh.GigaByte), Count: 1},
+		},
+		NICs: []hwcc.NICSignature{
+			{Model: "0x8086 0x1521", SpeedGbps: 1, Count: 1},
+			{Model: "0x8086 0x1572", SpeedGbps: 10, Count: 2},
+		},
+	}, HostHardwareSignature(&host))
+
+	assert.Nil(t, HostHardwareSignature(&bmh.BareMetalHost{}))
+}
+
+func TestGroupHosts(t *testing.T) {
+	nics := []bmh.NIC{{Name: "eno1", Model: "0x8086 0x1572", SpeedGbps: 10}}
+	disks := []bmh.Storage{
+		{Name: "/dev/sda", SizeBytes: 480 * bmh.GigaByte},
+		{Name: "/dev/sdb", SizeBytes: 1920 * bmh.GigaByte, Rotational: true},
+	}
+	reordered := []bmh.Storage{disks[1], disks[0]}
+	reordered[0].Name, reordered[1].Name = "/dev/sda", "/dev/sdb"
+
+	testCases := []struct {
+		Scenario    string
+		Hosts       []bmh.BareMetalHost
+		Groups      [][]string
+		Uninspected int
+	}{
+		{
+			Scenario: "no-hosts",
+		},
+		{
+			Scenario: "identical",
+			Hosts: []bmh.BareMetalHost{
+				inventoryTestHost("host-1", 80, nics, disks),
+				inventoryTestHost("host-0", 80, nics, disks),
+			},
+			Groups: [][]string{{"host-0", "host-1"}},
+		},
+		{
+			Scenario: "disks-in-other-order",
+			Hosts: []bmh.BareMetalHost{
+				inventoryTestHost("host-0", 80, nics, disks),
+				inventoryTestHost("host-1", 80, nics, reordered),
+			},
+			Groups: [][]string{{"host-0", "host-1"}},
+		},
+		{
+			Scenario: "different-cpu-count",
+			Hosts: []bmh.BareMetalHost{
+				inventoryTestHost("host-0", 40, nics, disks),
+				inventoryTestHost("host-1", 80, nics, disks),
+				inventoryTestHost("host-2", 80, nics, disks),
+			},
+			Groups: [][]string{{"host-1", "host-2"}, {"host-0"}},
+		},
+		{
+			Scenario: "different-disk-layout",
+			Hosts: []bmh.BareMetalHost{
+				inventoryTestHost("host-0", 80, nics, disks[:1]),
+				inventoryTestHost("host-1", 80, nics, disks),
+			},
+			Groups: [][]string{{"host-0"}, {"host-1"}},
+		},
+		{
+			Scenario: "uninspected",
+			Hosts: []bmh.BareMetalHost{
+				{ObjectMeta: metav1.ObjectMeta{Name: "host-0"}},
+				inventoryTestHost("host-1", 80, nics, disks),
+			},
+			Groups:      [][]string{{"host-1"}},
+			Uninspected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			groups, uninspected := GroupHosts(tc.Hosts)
+			assert.Equal(t, tc.Uninspected, uninspected)
+			assert.Len(t, groups, len(tc.Groups))
+			for i := range groups {
+				assert.Equal(t, tc.Groups[i], groups[i].Hosts)
+				assert.Equal(t, len(tc.Groups[i]), groups[i].Count)
+			}
+		})
+	}
+}
@@ -20,15 +20,9 @@ func checkNICs(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bo
 		nicDetails.MaximumCount,
 		len(host.Status.HardwareDetails.NIC),
 	)
-	log.Info("NIC",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"minCount", nicDetails.MinimumCount,
-		"maxCount", nicDetails.MaximumCount,
-		"actualCount", len(host.Status.HardwareDetails.NIC),
-		"ok", ok,
-	)
+	logCheck(profile, host, "nic-count",
+		valueRange{nicDetails.MinimumCount, nicDetails.MaximumCount},
+		len(host.Status.HardwareDetails.NIC), ok)
 
 	if !ok {
 		return false
@@ -46,15 +40,7 @@ func checkNICs(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bo
 		nicVendors,
 		nicDetails.NicSelector.Vendor,
 	)
-
-	log.Info("NIC",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"Require Nics vendor", nicDetails.NicSelector.Vendor,
-		"Actual Nics Vendor", nicVendors,
-		"ok", ok,
-	)
+	logCheck(profile, host, "nic-vendor", nicDetails.NicSelector.Vendor, nicVendors, ok)
 	if !ok {
 		return false
 	}
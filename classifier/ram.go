@@ -12,24 +12,20 @@ func checkRAM(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) boo
 		return true
 	}
 
-	// The size reported on the host is in MiB and the classification
-	// rule is given in GB, so we have to convert the values to the
-	// same units. Reducing MiB to GB loses detail, so we convert GB
-	// to MiB.
-	actualSize := host.Status.HardwareDetails.RAMMebibytes
-	minSize := ramDetails.MinimumSizeGB * 1024
-	maxSize := ramDetails.MaximumSizeGB * 1024
-
-	ok := checkRangeInt(minSize, maxSize, actualSize)
-	log.Info("RAM",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"minSize", minSize,
-		"maxSize", maxSize,
-		"actualSize", actualSize,
-		"ok", ok,
+	// The size reported on the host is in MiB, the deprecated
+	// classification rule is given in GiB despite its name.
+	actualSize := bmh.Capacity(host.Status.HardwareDetails.RAMMebibytes) * bmh.MebiByte
+	minSize, maxSize := sizeBounds(
+		ramDetails.MinimumSize,
+		ramDetails.MaximumSize,
+		int64(ramDetails.MinimumSizeGB),
+		int64(ramDetails.MaximumSizeGB),
+		int64(bmh.GibiByte),
+		ramDetails.SizeTolerancePercent,
 	)
 
+	ok := checkRangeCapacity(minSize, maxSize, actualSize)
+	logCheck(profile, host, "ram-size", valueRange{minSize, maxSize}, actualSize, ok)
+
 	return ok
 }
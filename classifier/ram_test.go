@@ -68,6 +68,58 @@ func TestCheckRAM(t *testing.T) {
 			Actual:   32 * (1024 ^ 2),
 			Expected: false,
 		},
+		{
+			Scenario: "quantity-within",
+			Rule: &hwcc.Ram{
+				MinimumSize: resourceQuantity("384Gi"),
+				MaximumSize: resourceQuantity("384Gi"),
+			},
+			Actual:   384 * 1024,
+			Expected: true,
+		},
+		{
+			Scenario: "quantity-under-min",
+			Rule: &hwcc.Ram{
+				MinimumSize: resourceQuantity("384Gi"),
+			},
+			Actual:   383 * 1024,
+			Expected: false,
+		},
+		{
+			Scenario: "quantity-precedence",
+			Rule: &hwcc.Ram{
+				MinimumSizeGB: 512,
+				MinimumSize:   resourceQuantity("384Gi"),
+			},
+			Actual:   384 * 1024,
+			Expected: true,
+		},
+		{
+			Scenario: "decimal-quantity",
+			Rule: &hwcc.Ram{
+				MaximumSize: resourceQuantity("384G"),
+			},
+			Actual:   384 * 1024,
+			Expected: false,
+		},
+		{
+			Scenario: "within-tolerance",
+			Rule: &hwcc.Ram{
+				MinimumSize:          resourceQuantity("384Gi"),
+				SizeTolerancePercent: 1,
+			},
+			Actual:   382 * 1024,
+			Expected: true,
+		},
+		{
+			Scenario: "outside-tolerance",
+			Rule: &hwcc.Ram{
+				MinimumSize:          resourceQuantity("384Gi"),
+				SizeTolerancePercent: 1,
+			},
+			Actual:   380 * 1024,
+			Expected: false,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.Scenario, func(t *testing.T) {
@@ -0,0 +1,50 @@
+package classifier
+
+import (
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// defaultWeight is used for characteristics without an explicit
+// weight.
+const defaultWeight = 1
+
+// ProfileScoreForHost computes the fit score of the host for the
+// profile, in percent. Each characteristic set in the profile
+// contributes its weight when the host satisfies it. A profile
+// without any characteristics scores 100 for every host.
+func ProfileScoreForHost(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) int {
+	weights := hwcc.ScoringWeights{}
+	if profile.Spec.Scoring != nil {
+		weights = profile.Spec.Scoring.Weights
+	}
+
+	total := 0
+	matched := 0
+	for _, c := range characteristics {
+		if !c.isSet(&profile.Spec.HardwareCharacteristics) {
+			continue
+		}
+		weight := c.weight(&weights)
+		if weight <= 0 {
+			weight = defaultWeight
+		}
+		total += weight
+		if c.check(profile, host) {
+			matched += weight
+		}
+	}
+
+	if total == 0 {
+		return 100
+	}
+	score := matched * 100 / total
+	log.V(1).Info("scored host",
+		"host", host.Name,
+		"namespace", host.Namespace,
+		"profile", profile.Name,
+		"score", score,
+	)
+	return score
+}
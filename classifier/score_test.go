@@ -0,0 +1,108 @@
+package classifier
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestProfileScoreForHost(t *testing.T) {
+	host := bmh.BareMetalHost{
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				RAMMebibytes: 64 * 1024,
+				CPU: bmh.CPU{
+					Arch:  "x86_64",
+					Count: 16,
+				},
+				NIC: []bmh.NIC{{Name: "eth0", Model: "0x8086 0x1572"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		Scenario        string
+		Characteristics hwcc.HardwareCharacteristics
+		Scoring         *hwcc.Scoring
+		Score           int
+		Matches         bool
+	}{
+		{
+			Scenario: "no-characteristics",
+			Score:    100,
+			Matches:  true,
+		},
+		{
+			Scenario: "all-match",
+			Characteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+				Ram: &hwcc.Ram{MinimumSizeGB: 32},
+			},
+			Score:   100,
+			Matches: true,
+		},
+		{
+			Scenario: "half-match-strict",
+			Characteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+				Ram: &hwcc.Ram{MinimumSizeGB: 128},
+			},
+			Score:   50,
+			Matches: false,
+		},
+		{
+			Scenario: "half-match-scoring",
+			Characteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+				Ram: &hwcc.Ram{MinimumSizeGB: 128},
+			},
+			Scoring: &hwcc.Scoring{MinimumScore: 50},
+			Score:   50,
+			Matches: true,
+		},
+		{
+			Scenario: "weighted",
+			Characteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+				Ram: &hwcc.Ram{MinimumSizeGB: 128},
+				Nic: &hwcc.Nic{MinimumCount: 1},
+			},
+			Scoring: &hwcc.Scoring{
+				MinimumScore: 80,
+				Weights: hwcc.ScoringWeights{
+					Cpu: 3,
+					Ram: 1,
+				},
+			},
+			Score:   80,
+			Matches: true,
+		},
+		{
+			Scenario: "below-minimum",
+			Characteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 32},
+				Ram: &hwcc.Ram{MinimumSizeGB: 128},
+				Nic: &hwcc.Nic{MinimumCount: 1},
+			},
+			Scoring: &hwcc.Scoring{MinimumScore: 50},
+			Score:   33,
+			Matches: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := hwcc.HardwareClassification{
+				Spec: hwcc.HardwareClassificationSpec{
+					HardwareCharacteristics: tc.Characteristics,
+					Scoring:                 tc.Scoring,
+				},
+			}
+			assert.Equal(t, tc.Score, ProfileScoreForHost(&profile, &host))
+			assert.Equal(t, tc.Matches, ProfileMatchesHost(&profile, &host))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package classifier
+
+import (
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// sizeBounds returns the size range of a rule in bytes. The quantities
+// take precedence over the deprecated sizes, which are given in units.
+// The range is widened by tolerancePercent in both directions; a zero
+// bound stays unbounded.
+func sizeBounds(min, max *resource.Quantity, legacyMin, legacyMax, unit int64, tolerancePercent int) (bmh.Capacity, bmh.Capacity) {
+	minSize := legacyMin * unit
+	if min != nil {
+		minSize = min.Value()
+	}
+	maxSize := legacyMax * unit
+	if max != nil {
+		maxSize = max.Value()
+	}
+
+	minSize -= minSize * int64(tolerancePercent) / 100
+	maxSize += maxSize * int64(tolerancePercent) / 100
+	return bmh.Capacity(minSize), bmh.Capacity(maxSize)
+}
@@ -0,0 +1,64 @@
+package classifier
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func resourceQuantity(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
+func TestSizeBounds(t *testing.T) {
+	testCases := []struct {
+		Scenario    string
+		Min, Max    *resource.Quantity
+		LegacyMin   int64
+		LegacyMax   int64
+		Tolerance   int
+		ExpectedMin bmh.Capacity
+		ExpectedMax bmh.Capacity
+	}{
+		{
+			Scenario: "unbounded",
+		},
+		{
+			Scenario:    "legacy",
+			LegacyMin:   1,
+			LegacyMax:   2,
+			ExpectedMin: bmh.GigaByte,
+			ExpectedMax: 2 * bmh.GigaByte,
+		},
+		{
+			Scenario:    "quantity",
+			Min:         resourceQuantity("1.5Gi"),
+			LegacyMin:   1,
+			LegacyMax:   2,
+			ExpectedMin: 1536 * bmh.MebiByte,
+			ExpectedMax: 2 * bmh.GigaByte,
+		},
+		{
+			Scenario:    "tolerance",
+			Min:         resourceQuantity("100G"),
+			Max:         resourceQuantity("200G"),
+			Tolerance:   10,
+			ExpectedMin: 90 * bmh.GigaByte,
+			ExpectedMax: 220 * bmh.GigaByte,
+		},
+		{
+			Scenario:  "tolerance-unbounded",
+			Tolerance: 10,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			min, max := sizeBounds(tc.Min, tc.Max, tc.LegacyMin, tc.LegacyMax, int64(bmh.GigaByte), tc.Tolerance)
+			assert.Equal(t, tc.ExpectedMin, min)
+			assert.Equal(t, tc.ExpectedMax, max)
+		})
+	}
+}
@@ -15,28 +15,16 @@ func checkSystemVendor(profile *hwcc.HardwareClassification, host *bmh.BareMetal
 	}
 
 	ok := checkString(systemVendorDetails.Manufacturer, host.Status.HardwareDetails.SystemVendor.Manufacturer)
-	log.Info("System Vendor",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"Manufacturer", systemVendorDetails.Manufacturer,
-		"actual Manufacturer", host.Status.HardwareDetails.SystemVendor.Manufacturer,
-		"ok", ok,
-	)
+	logCheck(profile, host, "system-vendor-manufacturer",
+		systemVendorDetails.Manufacturer, host.Status.HardwareDetails.SystemVendor.Manufacturer, ok)
 
 	if !ok {
 		return false
 	}
 
 	ok = checkSubString(systemVendorDetails.ProductName, host.Status.HardwareDetails.SystemVendor.ProductName)
-	log.Info("System Vendor",
-		"host", host.Name,
-		"profile", profile.Name,
-		"namespace", host.Namespace,
-		"ProductName", systemVendorDetails.ProductName,
-		"actual ProductName", host.Status.HardwareDetails.SystemVendor.ProductName,
-		"ok", ok,
-	)
+	logCheck(profile, host, "system-vendor-product-name",
+		systemVendorDetails.ProductName, host.Status.HardwareDetails.SystemVendor.ProductName, ok)
 	if !ok {
 		return false
 	}
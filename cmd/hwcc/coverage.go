@@ -0,0 +1,175 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+	"github.com/metal3-io/hardware-classification-controller/controllers"
+)
+
+// runCoverage prints, for every namespace, the hosts matching no
+// profile or several profiles, and the profiles subsumed by others, to
+// stdout, and the diagnostics to stderr.
+func runCoverage(args []string, stdout, stderr io.Writer) error {
+	var namespace string
+	var file string
+	var profilesFile string
+	var output string
+	flags := newFlagSet("coverage", stderr)
+	flags.StringVar(&namespace, "namespace", "",
+		"Namespace of the BareMetalHosts to analyse. All namespaces when unset.")
+	flags.StringVar(&file, "file", "",
+		"Read the BareMetalHosts and HardwareClassifications from a YAML or JSON file instead of the cluster. - reads the standard input.")
+	flags.StringVar(&profilesFile, "profiles", "",
+		"Read additional HardwareClassifications from a YAML or JSON file, e.g. generated profiles to check before applying them.")
+	flags.StringVar(&output, "output", "text",
+		"Output format, text or yaml.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if output != "text" && output != "yaml" {
+		return errors.Errorf("unknown output format %q", output)
+	}
+
+	var hosts []bmh.BareMetalHost
+	var profiles func(namespace string) ([]hwcc.HardwareClassification, error)
+	if file == "" {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		if hosts, err = listHosts(c, namespace); err != nil {
+			return err
+		}
+		profiles = func(namespace string) ([]hwcc.HardwareClassification, error) {
+			return controllers.NamespaceProfiles(context.Background(), c, namespace)
+		}
+	} else {
+		objects, err := readFile(file)
+		if err != nil {
+			return err
+		}
+		hosts = hostsInNamespace(objects.hosts, namespace)
+		profiles = func(namespace string) ([]hwcc.HardwareClassification, error) {
+			return profilesInNamespace(stderr, objects.profiles, namespace), nil
+		}
+	}
+
+	extraProfiles := []hwcc.HardwareClassification{}
+	if profilesFile != "" {
+		objects, err := readFile(profilesFile)
+		if err != nil {
+			return err
+		}
+		extraProfiles = objects.profiles
+	}
+
+	byNamespace, namespaces := hostsByNamespace(hosts)
+	report := map[string]*hwcc.ProfileCoverage{}
+	for _, ns := range namespaces {
+		nsProfiles, err := profiles(ns)
+		if err != nil {
+			return err
+		}
+		nsProfiles = append(nsProfiles, profilesInNamespace(stderr, extraProfiles, ns)...)
+		report[ns], err = classifier.DefaultEngine.Coverage(context.Background(), nsProfiles, byNamespace[ns])
+		if err != nil {
+			return err
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	if output == "yaml" {
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	} else {
+		for _, ns := range namespaces {
+			printCoverage(out, ns, len(byNamespace[ns]), report[ns])
+		}
+	}
+	return out.Flush()
+}
+
+// hostsByNamespace groups the hosts by namespace and returns the
+// namespaces in name order.
+func hostsByNamespace(hosts []bmh.BareMetalHost) (map[string][]bmh.BareMetalHost, []string) {
+	byNamespace := map[string][]bmh.BareMetalHost{}
+	namespaces := []string{}
+	for _, host := range hosts {
+		if _, ok := byNamespace[host.Namespace]; !ok {
+			namespaces = append(namespaces, host.Namespace)
+		}
+		byNamespace[host.Namespace] = append(byNamespace[host.Namespace], host)
+	}
+	sort.Strings(namespaces)
+	return byNamespace, namespaces
+}
+
+// profilesInNamespace returns the profiles read from a file applying to
+// the namespace. Profiles without namespace apply to every namespace,
+// as they would be created in the namespace they are applied to.
+// Templates are not read from files, so profiles using one are left
+// out and reported to diag.
+func profilesInNamespace(diag io.Writer, profiles []hwcc.HardwareClassification, namespace string) []hwcc.HardwareClassification {
+	selected := []hwcc.HardwareClassification{}
+	for _, profile := range profiles {
+		if profile.Namespace != "" && profile.Namespace != namespace {
+			continue
+		}
+		if profile.Spec.TemplateRef != nil {
+			fmt.Fprintf(diag, "skipping profile %q using a template\n", profile.Name)
+			continue
+		}
+		selected = append(selected, profile)
+	}
+	return selected
+}
+
+func printCoverage(w io.Writer, namespace string, hostCount int, coverage *hwcc.ProfileCoverage) {
+	fmt.Fprintf(w, "Namespace %s: %d hosts, %d profiles\n", namespace, hostCount, coverage.ProfileCount)
+
+	fmt.Fprintf(w, "  Hosts matching no profile: %d\n", coverage.UnclassifiedCount)
+	for _, host := range coverage.UnclassifiedHosts {
+		fmt.Fprintf(w, "    %s\n", host)
+	}
+
+	fmt.Fprintf(w, "  Hosts matching several profiles: %d\n", coverage.OverlappingCount)
+	for _, overlap := range coverage.OverlappingHosts {
+		fmt.Fprintf(w, "    %s: %s\n", overlap.Host, strings.Join(overlap.Profiles, ", "))
+	}
+
+	fmt.Fprintf(w, "  Subsumed profiles: %d\n", len(coverage.SubsumedProfiles))
+	for _, subsumed := range coverage.SubsumedProfiles {
+		fmt.Fprintf(w, "    %s by %s (%s)\n", subsumed.Profile, subsumed.SubsumedBy, subsumed.Basis)
+	}
+}
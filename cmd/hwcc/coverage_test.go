@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+)
+
+func TestProfilesInNamespace(t *testing.T) {
+	profiles := []hwcc.HardwareClassification{{}, {}, {}, {}}
+	profiles[0].Name, profiles[0].Namespace = "metal3", "metal3"
+	profiles[1].Name, profiles[1].Namespace = "other", "other"
+	profiles[2].Name = "any"
+	profiles[3].Name = "template"
+	profiles[3].Spec.TemplateRef = &hwcc.TemplateReference{Name: "template"}
+
+	diag := bytes.Buffer{}
+	names := []string{}
+	for _, profile := range profilesInNamespace(&diag, profiles, "metal3") {
+		names = append(names, profile.Name)
+	}
+	assert.Equal(t, []string{"metal3", "any"}, names)
+	assert.Equal(t, "skipping profile \"template\" using a template\n", diag.String())
+}
+
+func TestPrintCoverage(t *testing.T) {
+	objects, err := readObjects(strings.NewReader(hostsYAML + profilesYAML))
+	assert.NoError(t, err)
+	byNamespace, namespaces := hostsByNamespace(objects.hosts)
+	assert.Equal(t, []string{"metal3"}, namespaces)
+
+	coverage, err := classifier.NewEngine(1, nil).Coverage(context.TODO(),
+		profilesInNamespace(&bytes.Buffer{}, objects.profiles, "metal3"), byNamespace["metal3"])
+	assert.NoError(t, err)
+
+	out := bytes.Buffer{}
+	printCoverage(&out, "metal3", len(byNamespace["metal3"]), coverage)
+	assert.Equal(t, `Namespace metal3: 4 hosts, 2 profiles
+  Hosts matching no profile: 0
+  Hosts matching several profiles: 2
+    worker-0: any, large
+    worker-1: any, large
+  Subsumed profiles: 1
+    large by any (Characteristics)
+`, out.String())
+}
+
+func TestRunCoverage(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "objects.yaml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(hostsYAML+profilesYAML), 0600))
+
+	// The flags of a run do not leak into the next one.
+	for _, args := range [][]string{
+		{"--file", file, "--output", "yaml"},
+		{"--file", file},
+	} {
+		out := bytes.Buffer{}
+		diag := bytes.Buffer{}
+		assert.NoError(t, runCoverage(args, &out, &diag))
+		assert.Equal(t, len(args) == 2, strings.HasPrefix(out.String(), "Namespace metal3: 4 hosts"), "%v", args)
+		assert.Empty(t, diag.String())
+	}
+}
@@ -0,0 +1,225 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+)
+
+// runGenerate prints a profile for every group of hosts with identical
+// hardware to stdout, and the diagnostics to stderr.
+func runGenerate(args []string, stdout, stderr io.Writer) error {
+	var namespace string
+	var file string
+	var slackPercent int
+	var namePrefix string
+	flags := newFlagSet("generate", stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, generateUsage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&namespace, "namespace", "",
+		"Namespace of the BareMetalHosts to generate profiles for. All namespaces when unset.")
+	flags.StringVar(&file, "file", "",
+		"Read the BareMetalHosts from a YAML or JSON file, e.g. the output of kubectl get bmh -o yaml, instead of the cluster. - reads the standard input.")
+	flags.IntVar(&slackPercent, "slack", 0,
+		"Percentage by which the counts and sizes of the hosts may differ from the hosts the profile is generated from.")
+	flags.StringVar(&namePrefix, "name-prefix", "",
+		"Prefix of the names of the generated profiles.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if slackPercent < 0 || slackPercent > 100 {
+		return errors.New("slack must be between 0 and 100")
+	}
+
+	var hosts []bmh.BareMetalHost
+	if file == "" {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		if hosts, err = listHosts(c, namespace); err != nil {
+			return err
+		}
+	} else {
+		objects, err := readFile(file)
+		if err != nil {
+			return err
+		}
+		hosts = hostsInNamespace(objects.hosts, namespace)
+	}
+
+	out := bufio.NewWriter(stdout)
+	if err := generate(out, stderr, hosts, namePrefix, slackPercent); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+const generateUsage = `Usage: hwcc generate [flags]
+
+Generate a HardwareClassification for every group of hosts with identical
+hardware. A profile checks the disk count and sizes over the disks of all
+its types together, so hosts with another mix of disk types and sizes,
+e.g. 3 HDDs and 1 SSD instead of 1 HDD and 3 SSDs, match the same profile.
+
+Flags:
+`
+
+// mixedDisksComment precedes the profiles of hosts with disks of
+// several types or sizes, whose count and size ranges cover all of
+// them.
+const mixedDisksComment = "# disk count and sizes cover all the disk types, " +
+	"hosts with another mix of disk types and sizes match too\n"
+
+// profileManifest is a HardwareClassification without status, which
+// the controller sets.
+type profileManifest struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        profileMetadata                 `json:"metadata"`
+	Spec            hwcc.HardwareClassificationSpec `json:"spec"`
+}
+
+type profileMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// generate writes a profile for every group of hosts with identical
+// hardware in each namespace, the largest group first, preceded by a
+// comment listing its hosts. The hosts left out are reported to diag.
+func generate(w, diag io.Writer, hosts []bmh.BareMetalHost, namePrefix string, slackPercent int) error {
+	byNamespace, namespaces := hostsByNamespace(hosts)
+
+	first := true
+	for _, namespace := range namespaces {
+		groups, uninspected := classifier.GroupHosts(byNamespace[namespace])
+		if uninspected > 0 {
+			fmt.Fprintf(diag, "skipping %d hosts without hardware details in namespace %q\n",
+				uninspected, namespace)
+		}
+
+		names := map[string]bool{}
+		for _, group := range groups {
+			name := uniqueName(profileName(namePrefix, group.Hardware), names)
+			manifest := profileManifest{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: hwcc.GroupVersion.String(),
+					Kind:       "HardwareClassification",
+				},
+				Metadata: profileMetadata{Name: name, Namespace: namespace},
+				Spec: hwcc.HardwareClassificationSpec{
+					HardwareCharacteristics: classifier.SignatureCharacteristics(group.Hardware, slackPercent),
+				},
+			}
+			data, err := yaml.Marshal(manifest)
+			if err != nil {
+				return err
+			}
+
+			if !first {
+				fmt.Fprintln(w, "---")
+			}
+			first = false
+			hostsWord := "hosts"
+			if group.Count == 1 {
+				hostsWord = "host"
+			}
+			fmt.Fprintf(w, "# %d %s: %s\n", group.Count, hostsWord, strings.Join(group.Hosts, ", "))
+			if len(group.Hardware.Disks) > 1 {
+				fmt.Fprint(w, mixedDisksComment)
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// maxNameLength is the maximum length of the names of the generated
+// profiles, which are the names of their labels.
+const maxNameLength = validation.DNS1123LabelMaxLength
+
+// profileName describes the hardware of the group, e.g.
+// poweredge-r640-80cpu-192gib, in at most maxNameLength characters.
+func profileName(prefix string, signature hwcc.HardwareSignature) string {
+	parts := []string{}
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	switch {
+	case signature.ProductName != "":
+		parts = append(parts, signature.ProductName)
+	case signature.Manufacturer != "":
+		parts = append(parts, signature.Manufacturer)
+	}
+	parts = append(parts,
+		fmt.Sprintf("%dcpu", signature.CPU.Count),
+		fmt.Sprintf("%dgib", signature.RAMMebibytes/1024))
+	return truncateName(dnsName(strings.Join(parts, "-")), maxNameLength)
+}
+
+// dnsName lowercases the name and replaces the characters not allowed
+// in resource names with "-".
+func dnsName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// truncateName shortens the name to length characters, without a
+// trailing "-".
+func truncateName(name string, length int) string {
+	if len(name) > length {
+		name = name[:length]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// uniqueName appends a number to the name when it is already used,
+// shortening the name so the result still fits in maxNameLength.
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		unique = truncateName(name, maxNameLength-len(suffix)) + suffix
+	}
+	used[unique] = true
+	return unique
+}
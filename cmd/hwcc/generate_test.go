@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+)
+
+const hostsYAML = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: metal3.io/v1alpha1
+  kind: BareMetalHost
+  metadata:
+    name: worker-0
+    namespace: metal3
+  status:
+    hardware:
+      systemVendor:
+        manufacturer: Dell Inc.
+        productName: PowerEdge R640 (SKU=NotProvided)
+      cpu:
+        arch: x86_64
+        count: 80
+      ramMebibytes: 196608
+      nics:
+      - name: eno1
+        model: 0x8086 0x1572
+        speedGbps: 10
+      storage:
+      - name: /dev/sda
+        sizeBytes: 480103981056
+- apiVersion: metal3.io/v1alpha1
+  kind: BareMetalHost
+  metadata:
+    name: worker-1
+    namespace: metal3
+  status:
+    hardware:
+      systemVendor:
+        manufacturer: Dell Inc.
+        productName: PowerEdge R640 (SKU=NotProvided)
+      cpu:
+        arch: x86_64
+        count: 80
+      ramMebibytes: 196608
+      nics:
+      - name: eno1
+        model: 0x8086 0x1572
+        speedGbps: 10
+      storage:
+      - name: /dev/sda
+        sizeBytes: 480103981056
+- apiVersion: metal3.io/v1alpha1
+  kind: BareMetalHost
+  metadata:
+    name: not-inspected
+    namespace: metal3
+---
+apiVersion: metal3.io/v1alpha1
+kind: BareMetalHost
+metadata:
+  name: storage-0
+  namespace: metal3
+status:
+  hardware:
+    systemVendor:
+      manufacturer: Dell Inc.
+      productName: PowerEdge R640 (SKU=NotProvided)
+    cpu:
+      arch: x86_64
+      count: 40
+    ramMebibytes: 196608
+`
+
+func TestGenerate(t *testing.T) {
+	objects, err := readObjects(strings.NewReader(hostsYAML))
+	assert.NoError(t, err)
+	hosts := objects.hosts
+
+	out := bytes.Buffer{}
+	assert.NoError(t, generate(&out, &bytes.Buffer{}, hosts, "", 0))
+
+	documents := strings.Split(out.String(), "---\n")
+	assert.Len(t, documents, 2)
+	assert.True(t, strings.HasPrefix(documents[0], "# 2 hosts: worker-0, worker-1\n"))
+	assert.True(t, strings.HasPrefix(documents[1], "# 1 host: storage-0\n"))
+
+	// Each generated profile matches the hosts it was generated from.
+	for i, names := range [][]string{{"worker-0", "worker-1"}, {"storage-0"}} {
+		profile := hwcc.HardwareClassification{}
+		assert.NoError(t, yaml.Unmarshal([]byte(documents[i]), &profile))
+		assert.Equal(t, "metal3", profile.Namespace)
+		for _, host := range hosts {
+			if host.Status.HardwareDetails == nil {
+				continue
+			}
+			expected := false
+			for _, name := range names {
+				expected = expected || name == host.Name
+			}
+			assert.Equal(t, expected, classifier.ProfileMatchesHost(&profile, &host),
+				"profile=%s host=%s", profile.Name, host.Name)
+		}
+	}
+}
+
+const mixedDisksYAML = `
+apiVersion: metal3.io/v1alpha1
+kind: BareMetalHost
+metadata:
+  name: storage-0
+  namespace: metal3
+status:
+  hardware:
+    cpu:
+      count: 40
+    ramMebibytes: 196608
+    storage:
+    - {name: /dev/sda, rotational: true, sizeBytes: 4000787030016}
+    - {name: /dev/sdb, sizeBytes: 480103981056}
+    - {name: /dev/sdc, sizeBytes: 480103981056}
+    - {name: /dev/sdd, sizeBytes: 480103981056}
+---
+apiVersion: metal3.io/v1alpha1
+kind: BareMetalHost
+metadata:
+  name: storage-1
+  namespace: metal3
+status:
+  hardware:
+    cpu:
+      count: 40
+    ramMebibytes: 196608
+    storage:
+    - {name: /dev/sda, rotational: true, sizeBytes: 480103981056}
+    - {name: /dev/sdb, rotational: true, sizeBytes: 480103981056}
+    - {name: /dev/sdc, rotational: true, sizeBytes: 480103981056}
+    - {name: /dev/sdd, sizeBytes: 4000787030016}
+`
+
+func TestGenerateMixedDisks(t *testing.T) {
+	objects, err := readObjects(strings.NewReader(mixedDisksYAML))
+	assert.NoError(t, err)
+	hosts := objects.hosts
+
+	out := bytes.Buffer{}
+	assert.NoError(t, generate(&out, &bytes.Buffer{}, hosts[:1], "", 0))
+	assert.True(t, strings.HasPrefix(out.String(), "# 1 host: storage-0\n"+mixedDisksComment))
+
+	// The disk count and sizes are not checked per disk type, the host
+	// with the other mix of disks matches as well.
+	profile := hwcc.HardwareClassification{}
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &profile))
+	assert.True(t, classifier.ProfileMatchesHost(&profile, &hosts[0]))
+	assert.True(t, classifier.ProfileMatchesHost(&profile, &hosts[1]))
+}
+
+func TestRunGenerate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hosts.yaml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(hostsYAML), 0600))
+
+	// The flags of a run do not leak into the next one.
+	for _, args := range [][]string{
+		{"--file", file, "--name-prefix", "rack"},
+		{"--file", file},
+	} {
+		out := bytes.Buffer{}
+		diag := bytes.Buffer{}
+		assert.NoError(t, runGenerate(args, &out, &diag))
+		assert.Equal(t, len(args) > 2, strings.Contains(out.String(), "name: rack-"), "%v", args)
+		assert.Equal(t, 2, strings.Count(out.String(), "kind: HardwareClassification"))
+		assert.Equal(t, "skipping 1 hosts without hardware details in namespace \"metal3\"\n", diag.String())
+	}
+
+	diag := bytes.Buffer{}
+	assert.Error(t, runGenerate([]string{"--unknown"}, &bytes.Buffer{}, &diag))
+	assert.Contains(t, diag.String(), "-unknown")
+
+	diag = bytes.Buffer{}
+	assert.Equal(t, flag.ErrHelp, runGenerate([]string{"--help"}, &bytes.Buffer{}, &diag))
+	assert.Contains(t, diag.String(), "another mix of disk types and sizes")
+	assert.Contains(t, diag.String(), "-slack")
+}
+
+func TestProfileName(t *testing.T) {
+	testCases := []struct {
+		Scenario  string
+		Prefix    string
+		Signature hwcc.HardwareSignature
+		Expected  string
+	}{
+		{
+			Scenario: "product",
+			Signature: hwcc.HardwareSignature{
+				Manufacturer: "Dell Inc.",
+				ProductName:  "PowerEdge R640 (SKU=NotProvided)",
+				CPU:          hwcc.CPUSignature{Count: 80},
+				RAMMebibytes: 196608,
+			},
+			Expected: "poweredge-r640-sku-notprovided-80cpu-192gib",
+		},
+		{
+			Scenario: "manufacturer",
+			Signature: hwcc.HardwareSignature{
+				Manufacturer: "QEMU",
+				CPU:          hwcc.CPUSignature{Count: 2},
+				RAMMebibytes: 4096,
+			},
+			Expected: "qemu-2cpu-4gib",
+		},
+		{
+			Scenario: "prefix",
+			Prefix:   "Delivery-42",
+			Signature: hwcc.HardwareSignature{
+				CPU:          hwcc.CPUSignature{Count: 2},
+				RAMMebibytes: 4096,
+			},
+			Expected: "delivery-42-2cpu-4gib",
+		},
+		{
+			Scenario: "long product name",
+			Prefix:   "delivery-42",
+			Signature: hwcc.HardwareSignature{
+				ProductName:  "ThinkSystem SR650 V2 Rack Server - 2U Dual Processor - Xeon Scalable",
+				CPU:          hwcc.CPUSignature{Count: 64},
+				RAMMebibytes: 524288,
+			},
+			Expected: "delivery-42-thinksystem-sr650-v2-rack-server-2u-dual-processor",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			name := profileName(tc.Prefix, tc.Signature)
+			assert.Equal(t, tc.Expected, name)
+			assert.Empty(t, validation.IsDNS1123Label(name))
+			assert.Empty(t, validation.IsQualifiedName("hardwareclassification.metal3.io/"+name))
+		})
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	used := map[string]bool{}
+	assert.Equal(t, "name", uniqueName("name", used))
+	assert.Equal(t, "name-2", uniqueName("name", used))
+	assert.Equal(t, "name-3", uniqueName("name", used))
+	assert.Equal(t, "other", uniqueName("other", used))
+
+	// The number still fits in the name length.
+	long := strings.Repeat("a", maxNameLength-3) + "-bc"
+	assert.Equal(t, long, uniqueName(long, used))
+	assert.Equal(t, strings.Repeat("a", maxNameLength-3)+"-2", uniqueName(long, used))
+	assert.Equal(t, strings.Repeat("a", maxNameLength-3)+"-3", uniqueName(long, used))
+}
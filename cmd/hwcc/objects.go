@@ -0,0 +1,141 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/pkg/errors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// newClient returns a client of the cluster of the kubeconfig.
+func newClient() (client.Client, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+// listHosts lists the hosts of the namespace, or of all namespaces when
+// it is empty, from the cluster.
+func listHosts(c client.Client, namespace string) ([]bmh.BareMetalHost, error) {
+	hosts := bmh.BareMetalHostList{}
+	if err := c.List(context.Background(), &hosts, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "could not fetch host list")
+	}
+	return hosts.Items, nil
+}
+
+// objects are the resources read from a file.
+type objects struct {
+	hosts    []bmh.BareMetalHost
+	profiles []hwcc.HardwareClassification
+}
+
+// readFile reads the resources from the file, or from the standard
+// input for -.
+func readFile(path string) (*objects, error) {
+	if path == "-" {
+		return readObjects(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readObjects(f)
+}
+
+// document is a resource, or a list of resources as printed by
+// kubectl get -o yaml.
+type document struct {
+	Kind  string            `json:"kind"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// readObjects reads the hosts and profiles from a stream of YAML or
+// JSON documents, each either a resource or a list of resources. Other
+// resources are ignored.
+func readObjects(r io.Reader) (*objects, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	read := &objects{}
+	for {
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return read, nil
+			}
+			return nil, errors.Wrap(err, "could not read resources")
+		}
+		if err := read.add(raw); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (o *objects) add(raw json.RawMessage) error {
+	doc := document{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return errors.Wrap(err, "could not read resource")
+	}
+
+	var err error
+	switch {
+	case strings.HasSuffix(doc.Kind, "List"):
+		for _, item := range doc.Items {
+			if err = o.add(item); err != nil {
+				return err
+			}
+		}
+	case doc.Kind == "BareMetalHost":
+		host := bmh.BareMetalHost{}
+		if err = json.Unmarshal(raw, &host); err == nil {
+			o.hosts = append(o.hosts, host)
+		}
+	case doc.Kind == "HardwareClassification":
+		profile := hwcc.HardwareClassification{}
+		if err = json.Unmarshal(raw, &profile); err == nil {
+			o.profiles = append(o.profiles, profile)
+		}
+	}
+	return errors.Wrapf(err, "could not read %s", doc.Kind)
+}
+
+// hostsInNamespace returns the hosts of the namespace, or all the
+// hosts when it is empty.
+func hostsInNamespace(hosts []bmh.BareMetalHost, namespace string) []bmh.BareMetalHost {
+	if namespace == "" {
+		return hosts
+	}
+	selected := []bmh.BareMetalHost{}
+	for _, host := range hosts {
+		if host.Namespace == namespace {
+			selected = append(selected, host)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const profilesYAML = `
+---
+apiVersion: metal3.io/v1alpha1
+kind: HardwareClassification
+metadata:
+  name: large
+  namespace: metal3
+spec:
+  hardwareCharacteristics:
+    cpu:
+      minimumCount: 64
+---
+apiVersion: metal3.io/v1alpha1
+kind: HardwareClassification
+metadata:
+  name: any
+spec:
+  hardwareCharacteristics:
+    cpu:
+      minimumCount: 16
+`
+
+func TestReadObjects(t *testing.T) {
+	objects, err := readObjects(strings.NewReader(hostsYAML + profilesYAML))
+	assert.NoError(t, err)
+	hosts := objects.hosts
+	names := []string{}
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	assert.Equal(t, []string{"worker-0", "worker-1", "not-inspected", "storage-0"}, names)
+	assert.Equal(t, 80, hosts[0].Status.HardwareDetails.CPU.Count)
+	assert.Len(t, objects.profiles, 2)
+	assert.Equal(t, 16, objects.profiles[1].Spec.HardwareCharacteristics.Cpu.MinimumCount)
+
+	_, err = readObjects(strings.NewReader("kind: [List"))
+	assert.Error(t, err)
+	_, err = readObjects(strings.NewReader("kind: BareMetalHost\nspec: []"))
+	assert.Error(t, err)
+}
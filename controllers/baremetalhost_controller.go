@@ -18,15 +18,24 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
@@ -37,20 +46,45 @@ import (
 const (
 	defaultLabelName  = "hardwareclassification.metal3.io/"
 	defaultLabelValue = "matches"
+
+	// scoreAnnotationName is the prefix of the annotation recording
+	// the fit score of a profile on a host.
+	scoreAnnotationName = "score.hardwareclassification.metal3.io/"
 )
 
 // BareMetalHostReconciler reconciles a BareMetalHost object
 type BareMetalHostReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// FactLabels enables labelling every inspected host with its
+	// normalised hardware facts, independent of any profile.
+	FactLabels bool
+
+	// DriftLabels enables labelling hosts whose hardware changed
+	// since they were classified.
+	DriftLabels bool
+
+	// UnclassifiedLabels enables labelling inspected hosts matching
+	// no profile.
+	UnclassifiedLabels bool
+
+	// WaitForInspection holds the labels of hosts until an inspection
+	// completed, ignoring hardware details set otherwise.
+	WaitForInspection bool
+
+	// FailureLabel is the key of the label set on hosts in error,
+	// DefaultFailureLabel when empty.
+	FailureLabel string
 }
 
 func (r *BareMetalHostReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	_ = context.Background()
 	logger := r.Log.WithValues("host", req.NamespacedName)
 
-	logger.Info("reconciling")
+	logger.V(1).Info("reconciling")
 
 	host := &bmh.BareMetalHost{}
 	err := r.Get(context.TODO(), req.NamespacedName, host)
@@ -66,54 +100,185 @@ func (r *BareMetalHostReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		return ctrl.Result{}, errors.Wrap(err, "could not load host data")
 	}
 
-	if host.Status.HardwareDetails == nil {
-		logger.Info("no hardware details")
+	// The hardware details of a host being inspected are about to be
+	// replaced, the labels set for them no longer apply.
+	if inspectionInProgress(host) {
+		_, err = patchHost(context.TODO(), r, host, func(host *bmh.BareMetalHost) (bool, error) {
+			failureChanged := r.setFailureLabel(logger, host)
+			changed, err := r.clearClassification(logger, host)
+			return changed || failureChanged, err
+		})
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err,
+				fmt.Sprintf("failed to update host %s/%s", host.Namespace, host.Name))
+		}
+		logger.V(1).Info("inspection in progress")
 		return ctrl.Result{}, nil
 	}
 
+	if host.Status.HardwareDetails == nil {
+		logger.V(1).Info("no hardware details")
+		return ctrl.Result{}, r.labelFailedHost(context.TODO(), logger, host)
+	}
+
+	if r.WaitForInspection && !inspectionCompleted(host) {
+		logger.V(1).Info("waiting for inspection to complete")
+		return ctrl.Result{}, r.labelFailedHost(context.TODO(), logger, host)
+	}
+
+	var drift *hardwareDrift
+	var wasUnclassified bool
+	_, err = patchHost(context.TODO(), r, host, func(host *bmh.BareMetalHost) (bool, error) {
+		var changed bool
+		var err error
+		wasUnclassified = hasLabel(host, unclassifiedLabel)
+		failureChanged := r.setFailureLabel(logger, host)
+		drift, changed, err = r.classifyHost(logger, host)
+		return changed || failureChanged, err
+	})
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err,
+			fmt.Sprintf("failed to update host %s/%s", host.Namespace, host.Name))
+	}
+
+	if drift != nil {
+		r.Recorder.Eventf(host, corev1.EventTypeWarning, hardwareDriftEvent,
+			"hardware changed since last classification: %s", drift.String())
+	}
+	if !wasUnclassified && hasLabel(host, unclassifiedLabel) {
+		r.Recorder.Event(host, corev1.EventTypeWarning, unclassifiedEvent,
+			"host matches no classification profile")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// classifyHost updates the labels and annotations of the host for all
+// the profiles applying to it and reports whether the host changed,
+// along with the hardware drift detected.
+func (r *BareMetalHostReconciler) classifyHost(logger logr.Logger, host *bmh.BareMetalHost) (*hardwareDrift, bool, error) {
+	if host.Status.HardwareDetails == nil {
+		return nil, false, nil
+	}
+
+	changed := false
+	drift := detectDrift(host)
+	if drift != nil {
+		logger.Info("hardware changed since last classification",
+			"profiles", drift.Profiles, "changes", drift.String())
+		changed = recordDrift(host, drift)
+	} else if clearConfirmedDrift(host) {
+		logger.Info("hardware unchanged since the drift, clearing it")
+		changed = true
+	}
+
+	if r.FactLabels {
+		if setFactLabels(host) {
+			logger.Info("updated hardware fact labels")
+			changed = true
+		}
+	}
+
 	profileList := hwcc.HardwareClassificationList{}
 	opts := &client.ListOptions{
 		// We only want to apply profiles in the same namespace as the
 		// host.
-		Namespace: req.Namespace,
+		Namespace: host.Namespace,
 	}
-	err = r.List(context.TODO(), &profileList, opts)
+	err := r.List(context.TODO(), &profileList, opts)
 	if err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "could not fetch classification profiles")
+		return nil, false, errors.Wrap(err, "could not fetch classification profiles")
 	}
 
-	changed := false
+	matched := 0
 	for _, profile := range profileList.Items {
+		if reservedProfileName(&profile) {
+			logger.Error(errReservedProfileName, "skipping profile", "profile", profile.Name)
+			continue
+		}
+		err = resolveTemplate(context.TODO(), r, &profile)
+		if err != nil && profile.DeletionTimestamp.IsZero() {
+			// Leave the labels alone until the template is available.
+			logger.Error(err, "skipping profile", "profile", profile.Name)
+			continue
+		}
+		if profileMatches(&profile, host) {
+			matched++
+		}
 		labelKey, labelValue := getLabelDetails(&profile)
+		changed = applyProfile(logger, host, &profile, labelKey, labelValue,
+			scoreAnnotationName+profile.Name) || changed
+	}
 
-		switch {
-		case !profile.DeletionTimestamp.IsZero():
-			logger.Info("profile is being deleted", "profile", profile.Name)
-			changed = deleteLabel(host, labelKey) || changed
-			if changed {
-				logger.Info("removed label", "name", labelKey, "value", labelValue)
-			}
-		case !classifier.ProfileMatchesHost(&profile, host):
-			changed = deleteLabel(host, labelKey) || changed
-			if changed {
-				logger.Info("removed label", "name", labelKey, "value", labelValue)
-			}
-		default:
-			changed = setLabel(host, labelKey, labelValue) || changed
-			if changed {
-				logger.Info("set label", "name", labelKey, "value", labelValue)
-			}
-		}
+	clusterChanged, clusterMatched, err := r.applyClusterProfiles(logger, host)
+	if err != nil {
+		return nil, false, err
 	}
+	changed = clusterChanged || changed
+	matched += clusterMatched
 
-	if changed {
-		if err := r.Update(context.TODO(), host); err != nil {
-			return ctrl.Result{}, errors.Wrap(err,
-				fmt.Sprintf("failed to update host %s/%s", host.Namespace, host.Name))
+	if setUnclassifiedLabel(host, r.UnclassifiedLabels, matched == 0) {
+		logger.Info("updated unclassified label", "unclassified", hasLabel(host, unclassifiedLabel))
+		changed = true
+	}
+
+	changed = snapshotHardware(host) || changed
+	changed = setDriftLabel(host, r.DriftLabels) || changed
+	changed = setInspectionTimestamp(host) || changed
+	changed = deleteAnnotation(host, clearedProfilesAnnotation) || changed
+	return drift, changed, nil
+}
+
+// applyProfile sets or removes the label and score annotation of the
+// profile on the host. When the profile limits the number of hosts it
+// labels, the hosts selected by the profile reconciler are labelled,
+// and the label of the host is left alone until they are selected.
+func applyProfile(logger logr.Logger, host *bmh.BareMetalHost, profile *hwcc.HardwareClassification,
+	labelKey, labelValue, scoreKey string) bool {
+	changed := false
+
+	switch {
+	case !profile.DeletionTimestamp.IsZero():
+		logger.Info("profile is being deleted", "profile", profile.Name)
+		changed = deleteLabel(host, labelKey) || changed
+		if changed {
+			logger.Info("removed label", "name", labelKey, "value", labelValue)
+		}
+	case !classifier.DefaultCache.ProfileMatchesHost(profile, host):
+		if hasLabel(host, labelKey) && keepStaleLabel(profile, host) {
+			logger.Info("keeping label", "name", labelKey, "unlabelPolicy", profile.Spec.UnlabelPolicy)
+			break
+		}
+		changed = deleteLabel(host, labelKey) || changed
+		if changed {
+			logger.Info("removed label", "name", labelKey, "value", labelValue)
+		}
+	case profile.Spec.MaxHosts > 0 && selectionPending(profile):
+		logger.Info("waiting for host selection", "profile", profile.Name, "maxHosts", profile.Spec.MaxHosts)
+	case profile.Spec.MaxHosts > 0 && !hostSelected(profile, host):
+		logger.Info("host not selected", "profile", profile.Name, "maxHosts", profile.Spec.MaxHosts)
+		if hasLabel(host, labelKey) && keepStaleLabel(profile, host) {
+			logger.Info("keeping label", "name", labelKey, "unlabelPolicy", profile.Spec.UnlabelPolicy)
+			break
+		}
+		changed = deleteLabel(host, labelKey) || changed
+		if changed {
+			logger.Info("removed label", "name", labelKey, "value", labelValue)
+		}
+	default:
+		changed = setLabel(host, labelKey, labelValue) || changed
+		if changed {
+			logger.Info("set label", "name", labelKey, "value", labelValue)
 		}
 	}
 
-	return ctrl.Result{}, nil
+	if annotateScore(profile) {
+		score := strconv.Itoa(classifier.DefaultCache.ProfileScoreForHost(profile, host))
+		changed = setAnnotation(host, scoreKey, score) || changed
+	} else {
+		changed = deleteAnnotation(host, scoreKey) || changed
+	}
+	return changed
 }
 
 func getLabelDetails(profile *hwcc.HardwareClassification) (key, value string) {
@@ -130,6 +295,43 @@ func getLabelDetails(profile *hwcc.HardwareClassification) (key, value string) {
 	return
 }
 
+// profileMatches reports whether the host matches the profile, which
+// is not being deleted.
+func profileMatches(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bool {
+	return profile.DeletionTimestamp.IsZero() && classifier.DefaultCache.ProfileMatchesHost(profile, host)
+}
+
+// keepStaleLabel reports whether the unlabel policy of the profile
+// requires keeping its label on a host which no longer matches.
+func keepStaleLabel(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bool {
+	switch profile.Spec.UnlabelPolicy {
+	case hwcc.UnlabelPolicyNever:
+		return true
+	case hwcc.UnlabelPolicyOnlyIfNotProvisioned:
+		return hostInUse(host)
+	default:
+		return false
+	}
+}
+
+// hostInUse reports whether the host is provisioned or consumed.
+func hostInUse(host *bmh.BareMetalHost) bool {
+	if host.Spec.ConsumerRef != nil {
+		return true
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateProvisioning, bmh.StateProvisioned, bmh.StateExternallyProvisioned:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasLabel(host *bmh.BareMetalHost, labelKey string) bool {
+	_, ok := host.GetLabels()[labelKey]
+	return ok
+}
+
 func deleteLabel(host *bmh.BareMetalHost, labelKey string) bool {
 	labels := host.GetLabels()
 
@@ -166,20 +368,123 @@ func setLabel(host *bmh.BareMetalHost, labelKey string, labelValue string) bool
 	return true
 }
 
-func (r *BareMetalHostReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// annotateScore reports whether the fit score of the profile should
+// be recorded on the hosts.
+func annotateScore(profile *hwcc.HardwareClassification) bool {
+	return profile.DeletionTimestamp.IsZero() &&
+		profile.Spec.Scoring != nil &&
+		profile.Spec.Scoring.AnnotateHosts
+}
+
+func deleteAnnotation(host *bmh.BareMetalHost, key string) bool {
+	annotations := host.GetAnnotations()
+
+	if _, ok := annotations[key]; !ok {
+		return false
+	}
+
+	delete(annotations, key)
+	host.SetAnnotations(annotations)
+	return true
+}
+
+func setAnnotation(host *bmh.BareMetalHost, key string, value string) bool {
+	annotations := host.GetAnnotations()
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if val, ok := annotations[key]; ok && val == value {
+		return false
+	}
+
+	annotations[key] = value
+	host.SetAnnotations(annotations)
+	return true
+}
+
+// setFactLabels sets a label for each hardware fact of the host,
+// removing the labels of facts without a value.
+func setFactLabels(host *bmh.BareMetalHost) bool {
+	changed := false
+	for name, value := range classifier.HostFacts(host) {
+		labelKey := classifier.FactLabelPrefix + name
+		if value == "" {
+			changed = deleteLabel(host, labelKey) || changed
+			continue
+		}
+		changed = setLabel(host, labelKey, value) || changed
+	}
+	return changed
+}
+
+func (r *BareMetalHostReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 
 	mapper := hostMapper{
 		client: mgr.GetClient(),
 	}
+	allMapper := allHostsMapper{
+		client: mgr.GetClient(),
+	}
+	namespaceMapper := namespaceHostsMapper{
+		client: mgr.GetClient(),
+	}
+	templateMapper := templateHostsMapper{
+		client: mgr.GetClient(),
+	}
+	labelledMapper := labelledHostsMapper{
+		client: mgr.GetClient(),
+	}
 
+	// Only changes of the rules of a profile may change which of the
+	// hosts it applies to match it. Changes of its label value or its
+	// deletion only concern the hosts labelled for it. Status updates
+	// of the profiles do not change how hosts are labelled, except for
+	// the selection of profiles limiting the number of hosts they
+	// label, which is decided over all their hosts and moves the label
+	// between them.
+	labellingChanged := predicate.Or(profileLabelsChanged, profileSelectionChanged)
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&bmh.BareMetalHost{}).
+		For(&bmh.BareMetalHost{},
+			builder.WithPredicates(hostClassificationChanged)).
 		Named("baremetalhost").
 		Watches(&source.Kind{Type: &hwcc.HardwareClassification{}},
-			&handler.EnqueueRequestsFromMapFunc{ToRequests: &mapper}).
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &mapper},
+			builder.WithPredicates(profileRulesChanged)).
+		Watches(&source.Kind{Type: &hwcc.HardwareClassification{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &labelledMapper},
+			builder.WithPredicates(labellingChanged)).
+		Watches(&source.Kind{Type: &hwcc.ClusterHardwareClassification{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &allMapper},
+			builder.WithPredicates(profileRulesChanged)).
+		Watches(&source.Kind{Type: &hwcc.ClusterHardwareClassification{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &labelledMapper},
+			builder.WithPredicates(labellingChanged)).
+		Watches(&source.Kind{Type: &hwcc.HardwareClassificationTemplate{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &templateMapper},
+			builder.WithPredicates(profileSpecChanged)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &namespaceMapper},
+			builder.WithPredicates(namespaceLabelsChanged)).
+		Watches(&source.Kind{Type: &bmh.BareMetalHost{}},
+			forgetDeletedHosts(classifier.DefaultCache)).
+		WithOptions(options).
 		Complete(r)
 }
 
+// forgetDeletedHosts drops the classification results cached for the
+// deleted hosts. Their UID is only known from the delete event, hosts
+// deleted while they are inspected or whose last update is filtered
+// are not reconciled before they are gone.
+func forgetDeletedHosts(cache *classifier.Cache) handler.EventHandler {
+	return handler.Funcs{
+		DeleteFunc: func(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			cache.ForgetHost(e.Meta.GetUID())
+		},
+	}
+}
+
 type hostMapper struct {
 	client client.Client
 }
@@ -203,7 +508,7 @@ func (m *hostMapper) Map(obj handler.MapObject) []ctrl.Request {
 
 	requests := []ctrl.Request{}
 	for _, host := range bmhHostList.Items {
-		log.Info("found host", "name", host.Name)
+		log.V(1).Info("found host", "name", host.Name)
 		requests = append(requests, ctrl.Request{
 			NamespacedName: types.NamespacedName{
 				Name:      host.Name,
@@ -213,3 +518,49 @@ func (m *hostMapper) Map(obj handler.MapObject) []ctrl.Request {
 	}
 	return requests
 }
+
+// labelledHostsMapper enqueues the hosts labelled for a profile or
+// cluster profile and the hosts selected for it, for changes of its
+// label value, its deletion or its selection, which do not concern the
+// other hosts. The hosts are listed through the profile label index.
+type labelledHostsMapper struct {
+	client client.Client
+}
+
+func (m *labelledHostsMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("BareMetalHost").WithName("mapper")
+
+	var labelKey string
+	var selected []string
+	switch profile := obj.Object.(type) {
+	case *hwcc.HardwareClassification:
+		labelKey, _ = getLabelDetails(profile)
+		selected = profile.Status.SelectedHosts
+	case *hwcc.ClusterHardwareClassification:
+		labelKey, _ = getClusterLabelDetails(profile)
+		selected = profile.Status.SelectedHosts
+	default:
+		return nil
+	}
+
+	namespace := obj.Meta.GetNamespace()
+	hosts, err := labelledHosts(context.TODO(), m.client, namespace, labelKey)
+	if err != nil {
+		log.Error(err, "could not fetch labelled host list", "label", labelKey)
+		return nil
+	}
+
+	requests := hostRequests(hosts)
+	for _, key := range selected {
+		name := types.NamespacedName{Name: key, Namespace: namespace}
+		if namespace == "" {
+			parts := strings.SplitN(key, "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			name = types.NamespacedName{Name: parts[1], Namespace: parts[0]}
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: name})
+	}
+	return requests
+}
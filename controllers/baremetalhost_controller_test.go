@@ -4,10 +4,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
 )
 
 func TestGetLabelDetails(t *testing.T) {
@@ -174,3 +177,144 @@ func TestDeleteLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestSetFactLabels(t *testing.T) {
+	host := bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+			Labels: map[string]string{
+				"hwcc.metal3.io/vendor": "old-vendor",
+				"unrelated":             "value",
+			},
+		},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				RAMMebibytes: 16384,
+				CPU: bmh.CPU{
+					Arch:  "x86_64",
+					Count: 8,
+				},
+			},
+		},
+	}
+
+	assert.True(t, setFactLabels(&host))
+	assert.Equal(t, map[string]string{
+		"hwcc.metal3.io/cpu-arch":   "x86_64",
+		"hwcc.metal3.io/cpu-count":  "8",
+		"hwcc.metal3.io/ram-gb":     "16",
+		"hwcc.metal3.io/nic-count":  "0",
+		"hwcc.metal3.io/disk-count": "0",
+		"unrelated":                 "value",
+	}, host.GetLabels())
+
+	assert.False(t, setFactLabels(&host))
+}
+
+func TestScoreAnnotation(t *testing.T) {
+	host := bmh.BareMetalHost{}
+
+	assert.True(t, setAnnotation(&host, "name", "50"))
+	assert.False(t, setAnnotation(&host, "name", "50"))
+	assert.Equal(t, map[string]string{"name": "50"}, host.GetAnnotations())
+
+	assert.True(t, deleteAnnotation(&host, "name"))
+	assert.False(t, deleteAnnotation(&host, "name"))
+	assert.Equal(t, map[string]string{}, host.GetAnnotations())
+
+	profile := hwcc.HardwareClassification{}
+	assert.False(t, annotateScore(&profile))
+	profile.Spec.Scoring = &hwcc.Scoring{AnnotateHosts: true}
+	assert.True(t, annotateScore(&profile))
+}
+
+func TestKeepStaleLabel(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Policy   hwcc.UnlabelPolicy
+		State    bmh.ProvisioningState
+		Consumed bool
+		Expected bool
+	}{
+		{
+			Scenario: "default",
+			State:    bmh.StateProvisioned,
+			Expected: false,
+		},
+		{
+			Scenario: "always",
+			Policy:   hwcc.UnlabelPolicyAlways,
+			State:    bmh.StateProvisioned,
+			Expected: false,
+		},
+		{
+			Scenario: "never",
+			Policy:   hwcc.UnlabelPolicyNever,
+			State:    bmh.StateReady,
+			Expected: true,
+		},
+		{
+			Scenario: "not-provisioned",
+			Policy:   hwcc.UnlabelPolicyOnlyIfNotProvisioned,
+			State:    bmh.StateReady,
+			Expected: false,
+		},
+		{
+			Scenario: "provisioned",
+			Policy:   hwcc.UnlabelPolicyOnlyIfNotProvisioned,
+			State:    bmh.StateProvisioned,
+			Expected: true,
+		},
+		{
+			Scenario: "externally-provisioned",
+			Policy:   hwcc.UnlabelPolicyOnlyIfNotProvisioned,
+			State:    bmh.StateExternallyProvisioned,
+			Expected: true,
+		},
+		{
+			Scenario: "consumed",
+			Policy:   hwcc.UnlabelPolicyOnlyIfNotProvisioned,
+			State:    bmh.StateReady,
+			Consumed: true,
+			Expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := hwcc.HardwareClassification{
+				Spec: hwcc.HardwareClassificationSpec{UnlabelPolicy: tc.Policy},
+			}
+			host := bmh.BareMetalHost{}
+			host.Status.Provisioning.State = tc.State
+			if tc.Consumed {
+				host.Spec.ConsumerRef = &corev1.ObjectReference{Name: "machine"}
+			}
+			assert.Equal(t, tc.Expected, keepStaleLabel(&profile, &host))
+		})
+	}
+}
+
+func TestForgetDeletedHosts(t *testing.T) {
+	cache := classifier.NewCache()
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "host", Namespace: "metal3", UID: "host-uid"},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{CPU: bmh.CPU{Count: 8}},
+		},
+	}
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "metal3", UID: "profile-uid"},
+	}
+	cache.ProfileMatchesHost(profile, host)
+	assert.Equal(t, 1, cache.Len())
+
+	// Only deletions drop the cached results, whatever the state of
+	// the host.
+	handler := forgetDeletedHosts(cache)
+	handler.Update(event.UpdateEvent{MetaOld: host, ObjectOld: host, MetaNew: host, ObjectNew: host}, nil)
+	assert.Equal(t, 1, cache.Len())
+	handler.Delete(event.DeleteEvent{Meta: host, Object: host, DeleteStateUnknown: true}, nil)
+	assert.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("Host label updates", func() {
+	var host *bmh.BareMetalHost
+	var key types.NamespacedName
+
+	BeforeEach(func() {
+		host = &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "host-",
+				Namespace:    "default",
+				Labels:       map[string]string{"owner": "bmo"},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), host)).To(Succeed())
+		key = types.NamespacedName{Name: host.Name, Namespace: host.Namespace}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(context.TODO(), host)).To(Succeed())
+	})
+
+	It("keeps the changes of other writers", func() {
+		stale := host.DeepCopy()
+
+		concurrent := host.DeepCopy()
+		concurrent.Labels["bmo"] = "changed"
+		concurrent.Spec.Online = true
+		Expect(k8sClient.Update(context.TODO(), concurrent)).To(Succeed())
+
+		calls := 0
+		changed, err := patchHost(context.TODO(), k8sClient, stale, func(host *bmh.BareMetalHost) (bool, error) {
+			calls++
+			return setLabel(host, "hardwareclassification.metal3.io/profile", "matches"), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(calls).To(Equal(1))
+
+		stored := &bmh.BareMetalHost{}
+		Expect(k8sClient.Get(context.TODO(), key, stored)).To(Succeed())
+		Expect(stored.Spec.Online).To(BeTrue())
+		Expect(stored.Labels).To(Equal(map[string]string{
+			"owner": "bmo",
+			"bmo":   "changed",
+			"hardwareclassification.metal3.io/profile": "matches",
+		}))
+
+		managers := []string{}
+		for _, entry := range stored.ManagedFields {
+			managers = append(managers, entry.Manager)
+		}
+		Expect(managers).To(ContainElement(FieldManager))
+	})
+
+	It("labels hosts matching a profile", func() {
+		profile := &hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "profile-",
+				Namespace:    "default",
+			},
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 4},
+				},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), profile)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(context.TODO(), profile)).To(Succeed())
+		}()
+
+		host.Status = bmh.BareMetalHostStatus{
+			OperationalStatus: bmh.OperationalStatusOK,
+			HardwareDetails: &bmh.HardwareDetails{
+				CPU:     bmh.CPU{Count: 8, Flags: []string{}},
+				NIC:     []bmh.NIC{},
+				Storage: []bmh.Storage{},
+			},
+		}
+		Expect(k8sClient.Status().Update(context.TODO(), host)).To(Succeed())
+
+		r := &BareMetalHostReconciler{
+			Client:   k8sClient,
+			Log:      ctrl.Log.WithName("test"),
+			Scheme:   scheme.Scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+		_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		stored := &bmh.BareMetalHost{}
+		Expect(k8sClient.Get(context.TODO(), key, stored)).To(Succeed())
+		labelKey, labelValue := getLabelDetails(profile)
+		Expect(stored.Labels).To(HaveKeyWithValue(labelKey, labelValue))
+		Expect(stored.Labels).To(HaveKeyWithValue("owner", "bmo"))
+		Expect(stored.Status.HardwareDetails).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,298 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+const (
+	// clusterLabelName is the prefix of the labels set for cluster
+	// profiles, distinct from the namespaced profile labels so both
+	// kinds can use the same names.
+	clusterLabelName = "cluster.hardwareclassification.metal3.io/"
+
+	// clusterScoreAnnotationName is the prefix of the annotation
+	// recording the fit score of a cluster profile on a host.
+	clusterScoreAnnotationName = "score.cluster.hardwareclassification.metal3.io/"
+)
+
+// profileFromCluster returns a namespaced profile equivalent to the
+// cluster profile, so the classifier and status helpers can be shared
+// by both kinds.
+func profileFromCluster(clusterProfile *hwcc.ClusterHardwareClassification) *hwcc.HardwareClassification {
+	return &hwcc.HardwareClassification{
+		ObjectMeta: *clusterProfile.ObjectMeta.DeepCopy(),
+		Spec:       *clusterProfile.Spec.HardwareClassificationSpec.DeepCopy(),
+		Status:     *clusterProfile.Status.DeepCopy(),
+	}
+}
+
+func getClusterLabelDetails(clusterProfile *hwcc.ClusterHardwareClassification) (key, value string) {
+	_, value = getLabelDetails(profileFromCluster(clusterProfile))
+	return clusterLabelName + clusterProfile.Name, value
+}
+
+// namespaceSelected reports whether the namespaceSelector of the
+// cluster profile selects the namespace.
+func namespaceSelected(clusterProfile *hwcc.ClusterHardwareClassification, namespace *corev1.Namespace) (bool, error) {
+	if clusterProfile.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(clusterProfile.Spec.NamespaceSelector)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid namespace selector")
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// clusterHosts returns the hosts of all the namespaces selected by the
+// cluster profile.
+func clusterHosts(ctx context.Context, c client.Reader, clusterProfile *hwcc.ClusterHardwareClassification) ([]bmh.BareMetalHost, error) {
+	namespaceList := corev1.NamespaceList{}
+	if err := c.List(ctx, &namespaceList); err != nil {
+		return nil, errors.Wrap(err, "could not fetch namespace list")
+	}
+	// The hosts are listed per selected namespace, through the
+	// namespace index of the cache.
+	hosts := []bmh.BareMetalHost{}
+	for i := range namespaceList.Items {
+		ok, err := namespaceSelected(clusterProfile, &namespaceList.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		hostList := bmh.BareMetalHostList{}
+		err = c.List(ctx, &hostList, client.InNamespace(namespaceList.Items[i].Name))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch host list")
+		}
+		hosts = append(hosts, hostList.Items...)
+	}
+	return hosts, nil
+}
+
+// applyClusterProfiles sets or removes the labels of all the cluster
+// profiles on the host and reports whether the host changed, along
+// with the number of profiles it matches. Profiles whose
+// namespaceSelector does not select the namespace of the host are
+// removed from it.
+func (r *BareMetalHostReconciler) applyClusterProfiles(logger logr.Logger, host *bmh.BareMetalHost) (bool, int, error) {
+	ctx := context.TODO()
+
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := r.List(ctx, &clusterProfileList); err != nil {
+		return false, 0, errors.Wrap(err, "could not fetch cluster classification profiles")
+	}
+	if len(clusterProfileList.Items) == 0 {
+		return false, 0, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: host.Namespace}, namespace); err != nil {
+		return false, 0, errors.Wrap(err, "could not load host namespace")
+	}
+
+	changed := false
+	matched := 0
+	for i := range clusterProfileList.Items {
+		clusterProfile := &clusterProfileList.Items[i]
+		profileLogger := logger.WithValues("clusterhardwareclassification", clusterProfile.Name)
+		labelKey, labelValue := getClusterLabelDetails(clusterProfile)
+		scoreKey := clusterScoreAnnotationName + clusterProfile.Name
+
+		selected, err := namespaceSelected(clusterProfile, namespace)
+		if err != nil {
+			// Leave the labels alone until the selector is fixed.
+			profileLogger.Error(err, "skipping cluster profile")
+			continue
+		}
+		if !selected {
+			if deleteLabel(host, labelKey) {
+				profileLogger.Info("namespace not selected, removed label", "name", labelKey)
+				changed = true
+			}
+			changed = deleteAnnotation(host, scoreKey) || changed
+			continue
+		}
+
+		profile := profileFromCluster(clusterProfile)
+		err = resolveTemplate(ctx, r, profile)
+		if err != nil && profile.DeletionTimestamp.IsZero() {
+			profileLogger.Error(err, "skipping cluster profile")
+			continue
+		}
+
+		changed = applyProfile(profileLogger, host, profile,
+			labelKey, labelValue, scoreKey) || changed
+		if profileMatches(profile, host) {
+			matched++
+		}
+	}
+	return changed, matched, nil
+}
+
+// allHostsMapper enqueues every host, for changes to cluster profiles
+// and templates.
+type allHostsMapper struct {
+	client client.Client
+}
+
+func (m *allHostsMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("BareMetalHost").WithName("mapper").
+		WithValues("ClusterHardwareClassification", obj.Meta.GetName())
+
+	bmhHostList := bmh.BareMetalHostList{}
+	if err := m.client.List(context.TODO(), &bmhHostList); err != nil {
+		log.Error(err, "could not fetch host list")
+		return nil
+	}
+	return hostRequests(bmhHostList.Items)
+}
+
+// namespaceHostsMapper enqueues the hosts of a namespace, for changes
+// to the labels used by namespace selectors.
+type namespaceHostsMapper struct {
+	client client.Client
+}
+
+func (m *namespaceHostsMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("BareMetalHost").WithName("mapper").
+		WithValues("Namespace", obj.Meta.GetName())
+
+	bmhHostList := bmh.BareMetalHostList{}
+	opts := &client.ListOptions{
+		Namespace: obj.Meta.GetName(),
+	}
+	if err := m.client.List(context.TODO(), &bmhHostList, opts); err != nil {
+		log.Error(err, "could not fetch host list")
+		return nil
+	}
+	return hostRequests(bmhHostList.Items)
+}
+
+func hostRequests(hosts []bmh.BareMetalHost) []ctrl.Request {
+	requests := []ctrl.Request{}
+	for _, host := range hosts {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      host.Name,
+				Namespace: host.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// clusterClassificationMapper enqueues every cluster profile, for
+// changes to namespaces.
+type clusterClassificationMapper struct {
+	client client.Client
+}
+
+func (m *clusterClassificationMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("ClusterHardwareClassification").WithName("mapper")
+
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := m.client.List(context.TODO(), &clusterProfileList); err != nil {
+		log.Error(err, "could not fetch cluster hardware classification list")
+		return nil
+	}
+	return clusterProfileRequests(clusterProfileList.Items)
+}
+
+// selectingClusterClassificationMapper enqueues the cluster profiles
+// selecting the namespace of a host, for changes to the hardware or
+// error state of the host.
+type selectingClusterClassificationMapper struct {
+	client client.Client
+}
+
+func (m *selectingClusterClassificationMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("ClusterHardwareClassification").WithName("mapper").
+		WithValues("BareMetalHost", fmt.Sprintf("%s/%s", obj.Meta.GetNamespace(), obj.Meta.GetName()))
+
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := m.client.List(context.TODO(), &clusterProfileList); err != nil {
+		log.Error(err, "could not fetch cluster hardware classification list")
+		return nil
+	}
+	if len(clusterProfileList.Items) == 0 {
+		return nil
+	}
+
+	namespace := &corev1.Namespace{}
+	err := m.client.Get(context.TODO(), types.NamespacedName{Name: obj.Meta.GetNamespace()}, namespace)
+	if err != nil {
+		log.Error(err, "could not load host namespace")
+		return nil
+	}
+
+	selecting := []hwcc.ClusterHardwareClassification{}
+	for i := range clusterProfileList.Items {
+		// Profiles with an invalid selector report it when
+		// reconciled.
+		selected, err := namespaceSelected(&clusterProfileList.Items[i], namespace)
+		if err != nil || selected {
+			selecting = append(selecting, clusterProfileList.Items[i])
+		}
+	}
+	return clusterProfileRequests(selecting)
+}
+
+// labelledClusterClassificationMapper enqueues the cluster profiles a
+// host is labelled for. Handlers map both the old and the new host of
+// an update, so cluster profiles losing the host are enqueued too.
+type labelledClusterClassificationMapper struct{}
+
+func (m *labelledClusterClassificationMapper) Map(obj handler.MapObject) []ctrl.Request {
+	requests := []ctrl.Request{}
+	for key := range obj.Meta.GetLabels() {
+		if strings.HasPrefix(key, clusterLabelName) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: strings.TrimPrefix(key, clusterLabelName)},
+			})
+		}
+	}
+	return requests
+}
+
+func clusterProfileRequests(clusterProfiles []hwcc.ClusterHardwareClassification) []ctrl.Request {
+	requests := []ctrl.Request{}
+	for _, clusterProfile := range clusterProfiles {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: clusterProfile.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestGetClusterLabelDetails(t *testing.T) {
+	clusterProfile := hwcc.ClusterHardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large"},
+	}
+	key, value := getClusterLabelDetails(&clusterProfile)
+	assert.Equal(t, "cluster.hardwareclassification.metal3.io/large", key)
+	assert.Equal(t, defaultLabelValue, value)
+
+	clusterProfile.Labels = map[string]string{"large": "yes"}
+	_, value = getClusterLabelDetails(&clusterProfile)
+	assert.Equal(t, "yes", value)
+}
+
+func TestNamespaceSelected(t *testing.T) {
+	namespace := corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "site-a",
+			Labels: map[string]string{"site": "true"},
+		},
+	}
+
+	testCases := []struct {
+		Scenario string
+		Selector *metav1.LabelSelector
+		Expected bool
+		Error    bool
+	}{
+		{
+			Scenario: "no-selector",
+			Expected: true,
+		},
+		{
+			Scenario: "empty-selector",
+			Selector: &metav1.LabelSelector{},
+			Expected: true,
+		},
+		{
+			Scenario: "match-labels",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"site": "true"},
+			},
+			Expected: true,
+		},
+		{
+			Scenario: "no-match",
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"site": "false"},
+			},
+			Expected: false,
+		},
+		{
+			Scenario: "invalid",
+			Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "site", Operator: "Bogus"},
+				},
+			},
+			Error: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			clusterProfile := hwcc.ClusterHardwareClassification{
+				Spec: hwcc.ClusterHardwareClassificationSpec{
+					NamespaceSelector: tc.Selector,
+				},
+			}
+			selected, err := namespaceSelected(&clusterProfile, &namespace)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, selected)
+		})
+	}
+}
+
+func TestProfileFromCluster(t *testing.T) {
+	clusterProfile := hwcc.ClusterHardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large"},
+		Spec: hwcc.ClusterHardwareClassificationSpec{
+			HardwareClassificationSpec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 8},
+				},
+				MaxHosts: 2,
+			},
+		},
+	}
+
+	profile := profileFromCluster(&clusterProfile)
+	assert.Equal(t, "large", profile.Name)
+	assert.Equal(t, clusterProfile.Spec.HardwareClassificationSpec, profile.Spec)
+
+	profile.Spec.HardwareCharacteristics.Cpu.MinimumCount = 16
+	assert.Equal(t, 8, clusterProfile.Spec.HardwareCharacteristics.Cpu.MinimumCount)
+}
+
+func TestClusterClassificationMappers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+
+	clusterProfile := func(name string, selector map[string]string) *hwcc.ClusterHardwareClassification {
+		return &hwcc.ClusterHardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: hwcc.ClusterHardwareClassificationSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: selector},
+			},
+		}
+	}
+	c := fake.NewFakeClientWithScheme(scheme,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "site-a",
+			Labels: map[string]string{"site": "a"},
+		}},
+		clusterProfile("all", nil),
+		clusterProfile("site-a", map[string]string{"site": "a"}),
+		clusterProfile("site-b", map[string]string{"site": "b"}),
+	)
+
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "host-0",
+			Namespace: "site-a",
+			Labels: map[string]string{
+				clusterLabelName + "site-b": defaultLabelValue,
+				defaultLabelName + "large":  defaultLabelValue,
+			},
+		},
+	}
+	obj := handler.MapObject{Meta: host, Object: host}
+
+	selectingMapper := selectingClusterClassificationMapper{client: c}
+	assert.ElementsMatch(t, []ctrl.Request{
+		{NamespacedName: types.NamespacedName{Name: "all"}},
+		{NamespacedName: types.NamespacedName{Name: "site-a"}},
+	}, selectingMapper.Map(obj))
+
+	labelledMapper := labelledClusterClassificationMapper{}
+	assert.Equal(t, []ctrl.Request{
+		{NamespacedName: types.NamespacedName{Name: "site-b"}},
+	}, labelledMapper.Map(obj))
+}
@@ -0,0 +1,176 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+	"github.com/metal3-io/hardware-classification-controller/utils"
+)
+
+// ClusterHardwareClassificationReconciler reconciles a ClusterHardwareClassification object
+type ClusterHardwareClassificationReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile keeps the finalizer and status of a cluster profile up to
+// date. The hosts are labelled by the BareMetalHost reconciler.
+func (r *ClusterHardwareClassificationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	logger := r.Log.WithValues("clusterhardwareclassification", req.Name)
+
+	clusterProfile := &hwcc.ClusterHardwareClassification{}
+	if err := r.Get(ctx, req.NamespacedName, clusterProfile); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Add a finalizer to newly created objects.
+	if clusterProfile.DeletionTimestamp.IsZero() &&
+		!utils.StringInList(clusterProfile.Finalizers, hwcc.Finalizer) {
+		logger.Info(
+			"adding finalizer",
+			"existingFinalizers", clusterProfile.Finalizers,
+			"newValue", hwcc.Finalizer,
+		)
+		clusterProfile.Finalizers = append(clusterProfile.Finalizers, hwcc.Finalizer)
+		if err := r.Update(ctx, clusterProfile); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to add finalizer")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Labels may remain on hosts in namespaces which are no longer
+	// selected, so look at all of them before deleting.
+	labelKey, _ := getClusterLabelDetails(clusterProfile)
+	labelled, err := labelledHosts(ctx, r, "", labelKey)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	labelledCount := len(labelled)
+
+	// Wait to delete the profile until no hosts are labelled as
+	// matching its rules.
+	if !clusterProfile.DeletionTimestamp.IsZero() {
+		if labelledCount > 0 {
+			logger.Info("waiting to delete", "labelledHosts", labelledCount)
+			return ctrl.Result{}, nil
+		}
+		clusterProfile.Finalizers = utils.FilterStringFromList(
+			clusterProfile.Finalizers, hwcc.Finalizer)
+		if err := r.Update(ctx, clusterProfile); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to remove finalizer")
+		}
+		classifier.DefaultCache.ForgetProfile(clusterProfile.UID)
+		logger.Info("deleting")
+		return ctrl.Result{}, nil
+	}
+
+	hosts, err := clusterHosts(ctx, r, clusterProfile)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	profile := profileFromCluster(clusterProfile)
+	templateErr := resolveTemplate(ctx, r, profile)
+	if templateErr != nil {
+		logger.Error(templateErr, "could not resolve template")
+	}
+
+	// The status is computed as for a namespaced profile, from the
+	// hosts of the selected namespaces. Hosts of namespaces which are
+	// no longer selected are not counted, even while they keep the
+	// label. The whole status is computed before writing it once, and
+	// only when it changed.
+	matchCount := 0
+	for i := range hosts {
+		if hasLabel(&hosts[i], labelKey) {
+			matchCount++
+		}
+	}
+	failedHosts := fetchFailedBmhHostList(bmh.BareMetalHostList{Items: hosts})
+	original := clusterProfile.DeepCopy()
+	setStatus(profile, labelKey, hosts, failedHosts, matchCount, templateErr)
+	clusterProfile.Status = profile.Status
+	if !equality.Semantic.DeepEqual(original.Status, clusterProfile.Status) {
+		logger.Info("updating status",
+			"matchStatus", clusterProfile.Status.ProfileMatchStatus,
+			"matched", clusterProfile.Status.MatchedCount,
+			"unmatched", clusterProfile.Status.UnmatchedCount,
+		)
+		err = r.Status().Patch(ctx, clusterProfile, client.MergeFrom(original))
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to update status")
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager will add watches for this controller
+func (r *ClusterHardwareClassificationReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	mapper := clusterClassificationMapper{
+		client: mgr.GetClient(),
+	}
+	selectingMapper := selectingClusterClassificationMapper{
+		client: mgr.GetClient(),
+	}
+	labelledMapper := labelledClusterClassificationMapper{}
+	templateMapper := templateClusterClassificationMapper{
+		client: mgr.GetClient(),
+	}
+
+	// As for namespaced profiles, most host updates only concern the
+	// cluster profiles the host is labelled for, the cluster profiles
+	// selecting its namespace are only enqueued when the hardware or
+	// error state of a host changes.
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hwcc.ClusterHardwareClassification{}).
+		Named("cluster-hardware-classification").
+		Watches(&source.Kind{Type: &bmh.BareMetalHost{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &labelledMapper},
+			builder.WithPredicates(hostMatchChanged)).
+		Watches(&source.Kind{Type: &bmh.BareMetalHost{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &selectingMapper},
+			builder.WithPredicates(hostInventoryChanged)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &mapper},
+			builder.WithPredicates(namespaceLabelsChanged)).
+		Watches(&source.Kind{Type: &hwcc.HardwareClassificationTemplate{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &templateMapper},
+			builder.WithPredicates(profileSpecChanged)).
+		WithOptions(options).
+		Complete(r)
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestClusterReconcileStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	clusterProfile := &hwcc.ClusterHardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "large",
+			Finalizers: []string{hwcc.Finalizer},
+		},
+		Spec: hwcc.ClusterHardwareClassificationSpec{
+			HardwareClassificationSpec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 16},
+				},
+			},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"site": "true"},
+			},
+		},
+	}
+	labelKey, labelValue := getClusterLabelDetails(clusterProfile)
+
+	host := func(namespace, name string, cpuCount int, labelled bool) *bmh.BareMetalHost {
+		host := newHostWithCPUs(name, cpuCount)
+		host.Namespace = namespace
+		if labelled {
+			host.Labels = map[string]string{labelKey: labelValue}
+		}
+		return &host
+	}
+	failed := host("site-a", "failed", 32, true)
+	failed.Status.OperationalStatus = bmh.OperationalStatusError
+	failed.Status.ErrorType = bmh.InspectionError
+	c := fake.NewFakeClientWithScheme(scheme,
+		clusterProfile,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "site-a",
+			Labels: map[string]string{"site": "true"},
+		}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "retired"}},
+		host("site-a", "large", 32, true),
+		host("site-a", "small", 8, false),
+		failed,
+		// Still labelled from when its namespace was selected.
+		host("retired", "large", 32, true),
+	)
+	r := &ClusterHardwareClassificationReconciler{
+		Client: c,
+		Log:    ctrl.Log.WithName("test"),
+		Scheme: scheme,
+	}
+	key := types.NamespacedName{Name: "large"}
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), key, clusterProfile))
+	assert.Equal(t, hwcc.ProfileMatchStatusMatched, clusterProfile.Status.ProfileMatchStatus)
+	assert.Equal(t, hwcc.MatchedCount(2), clusterProfile.Status.MatchedCount)
+	// The failed host is counted apart, not as unmatched.
+	assert.Equal(t, hwcc.UnmatchedCount(1), clusterProfile.Status.UnmatchedCount)
+	assert.Equal(t, hwcc.ErrorHosts(1), clusterProfile.Status.ErrorHosts)
+	assert.Equal(t, map[string]int{string(bmh.InspectionError): 1}, clusterProfile.Status.ErrorHostsByType)
+	if assert.Len(t, clusterProfile.Status.FailingHosts, 1) {
+		assert.Equal(t, "failed", clusterProfile.Status.FailingHosts[0].Name)
+	}
+	assert.Equal(t, map[string]int{hwcc.NoProvisioningState: 2}, clusterProfile.Status.MatchedHostsByState)
+	assert.NotNil(t, meta.FindStatusCondition(clusterProfile.Status.Conditions, hwcc.ConditionHardwareDrift))
+	assert.Equal(t, 2, clusterProfile.Status.EligibleCount)
+
+	// The status is only written when it changes.
+	resourceVersion := clusterProfile.ResourceVersion
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), key, clusterProfile))
+	assert.Equal(t, resourceVersion, clusterProfile.ResourceVersion)
+}
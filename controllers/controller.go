@@ -4,8 +4,22 @@ package controllers
 //
 // +kubebuilder:rbac:groups=metal3.io,resources=hardwareclassifications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=hardwareclassifications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=clusterhardwareclassifications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=clusterhardwareclassifications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=hardwareclassificationtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=hardwareinventories,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=hardwareinventories/status,verbs=get;update;patch
 
 // RBAC rules for BareMetalHost resources
 //
-// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts/status,verbs=get
+
+// RBAC rules for namespaces, used by the namespace selector of
+// ClusterHardwareClassification resources
+//
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// RBAC rules for events
+//
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
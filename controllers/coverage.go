@@ -0,0 +1,79 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// clusterProfilePrefix is prepended to the names of cluster profiles
+// in the coverage reports, so they are not mistaken for namespaced
+// profiles of the same name.
+const clusterProfilePrefix = "ClusterHardwareClassification/"
+
+// NamespaceProfiles returns the profiles applying to the hosts of the
+// namespace, with their templates resolved: the HardwareClassifications
+// of the namespace and the ClusterHardwareClassifications selecting it,
+// named ClusterHardwareClassification/<name>. Profiles being deleted,
+// with a reserved name or whose template or namespace selector is
+// invalid are left out, as they do not label hosts.
+func NamespaceProfiles(ctx context.Context, c client.Reader, namespace string) ([]hwcc.HardwareClassification, error) {
+	profileList := hwcc.HardwareClassificationList{}
+	if err := c.List(ctx, &profileList, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "could not fetch classification profiles")
+	}
+	profiles := []hwcc.HardwareClassification{}
+	for i := range profileList.Items {
+		profile := &profileList.Items[i]
+		if reservedProfileName(profile) || !profile.DeletionTimestamp.IsZero() ||
+			resolveTemplate(ctx, c, profile) != nil {
+			continue
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := c.List(ctx, &clusterProfileList); err != nil {
+		return nil, errors.Wrap(err, "could not fetch cluster classification profiles")
+	}
+	if len(clusterProfileList.Items) == 0 {
+		return profiles, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, errors.Wrap(err, "could not load namespace")
+	}
+	for i := range clusterProfileList.Items {
+		clusterProfile := &clusterProfileList.Items[i]
+		if selected, err := namespaceSelected(clusterProfile, ns); err != nil || !selected {
+			continue
+		}
+		profile := profileFromCluster(clusterProfile)
+		if !profile.DeletionTimestamp.IsZero() || resolveTemplate(ctx, c, profile) != nil {
+			continue
+		}
+		profile.Name = clusterProfilePrefix + profile.Name
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestNamespaceProfiles(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+
+	c := fake.NewFakeClientWithScheme(scheme,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "metal3",
+			Labels: map[string]string{"site": "true"},
+		}},
+		&hwcc.HardwareClassification{ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "metal3"}},
+		&hwcc.HardwareClassification{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other"}},
+		&hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing-template", Namespace: "metal3"},
+			Spec: hwcc.HardwareClassificationSpec{
+				TemplateRef: &hwcc.TemplateReference{Name: "missing"},
+			},
+		},
+		&hwcc.ClusterHardwareClassification{ObjectMeta: metav1.ObjectMeta{Name: "all"}},
+		&hwcc.ClusterHardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: "sites"},
+			Spec: hwcc.ClusterHardwareClassificationSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"site": "true"}},
+			},
+		},
+		&hwcc.ClusterHardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: "labs"},
+			Spec: hwcc.ClusterHardwareClassificationSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"lab": "true"}},
+			},
+		},
+	)
+
+	profiles, err := NamespaceProfiles(context.TODO(), c, "metal3")
+	assert.NoError(t, err)
+	names := []string{}
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"compute",
+		"ClusterHardwareClassification/all",
+		"ClusterHardwareClassification/sites",
+	}, names)
+}
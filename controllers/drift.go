@@ -0,0 +1,225 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+	"github.com/metal3-io/hardware-classification-controller/utils"
+)
+
+const (
+	driftAnnotationPrefix = "drift.hardwareclassification.metal3.io/"
+
+	// hardwareHashAnnotation records the digest of the hardware
+	// details the host was last classified with.
+	hardwareHashAnnotation = driftAnnotationPrefix + "hash"
+	// hardwareSnapshotAnnotation records the hardware facts the host
+	// was last classified with, used to describe a drift.
+	hardwareSnapshotAnnotation = driftAnnotationPrefix + "snapshot"
+	// hardwareDriftAnnotation records the last detected drift. It is
+	// kept until a later inspection finds the same hardware, or until
+	// removed by the user.
+	hardwareDriftAnnotation = driftAnnotationPrefix + "changes"
+	// hardwareDriftLabel is set on hosts with a recorded drift when
+	// drift labels are enabled.
+	hardwareDriftLabel = driftAnnotationPrefix + "detected"
+
+	// hardwareDriftEvent is the reason of the event emitted when the
+	// hardware of a host changes.
+	hardwareDriftEvent = "HardwareDrift"
+)
+
+// hardwareDrift describes a change of the hardware of a host since it
+// was last classified.
+type hardwareDrift struct {
+	// Profiles lists the profiles the host was labelled for when the
+	// change was detected.
+	Profiles []string                `json:"profiles"`
+	Changes  []classifier.FactChange `json:"changes"`
+}
+
+func (d *hardwareDrift) String() string {
+	if len(d.Changes) == 0 {
+		return "hardware details changed"
+	}
+	changes := make([]string, 0, len(d.Changes))
+	for _, change := range d.Changes {
+		changes = append(changes, fmt.Sprintf("%s %q -> %q", change.Fact, change.Old, change.New))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// detectDrift compares the hardware of the host with the snapshot
+// taken when it was last classified, returning nil when the hardware
+// did not change or no snapshot exists.
+func detectDrift(host *bmh.BareMetalHost) *hardwareDrift {
+	oldHash, ok := host.GetAnnotations()[hardwareHashAnnotation]
+	if !ok || oldHash == classifier.HardwareHash(host) {
+		return nil
+	}
+	// Digests computed by earlier versions cover other details, the
+	// snapshot is taken again instead.
+	if !strings.HasPrefix(oldHash, classifier.HardwareHashPrefix) {
+		return nil
+	}
+
+	oldFacts := map[string]string{}
+	if snapshot, ok := host.GetAnnotations()[hardwareSnapshotAnnotation]; ok {
+		// A snapshot we cannot read still tells us the hardware
+		// changed, we just cannot say how.
+		_ = json.Unmarshal([]byte(snapshot), &oldFacts)
+	}
+
+	// Profiles whose labels were removed while the host was inspected
+	// again still had it before the hardware changed.
+	profiles := labelledProfiles(host)
+	for _, name := range clearedProfiles(host) {
+		if !utils.StringInList(profiles, name) {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles)
+
+	return &hardwareDrift{
+		Profiles: profiles,
+		Changes:  classifier.DiffFacts(oldFacts, classifier.HostFacts(host)),
+	}
+}
+
+// recordDrift stores the drift in an annotation of the host.
+func recordDrift(host *bmh.BareMetalHost, drift *hardwareDrift) bool {
+	data, err := json.Marshal(drift)
+	if err != nil {
+		return false
+	}
+	return setAnnotation(host, hardwareDriftAnnotation, string(data))
+}
+
+// clearConfirmedDrift removes the drift recorded on the host once an
+// inspection after the one which detected it found the same hardware,
+// the host being classified with the new hardware since. Hosts without
+// inspection history keep the drift until it is removed by the user.
+func clearConfirmedDrift(host *bmh.BareMetalHost) bool {
+	if _, ok := host.GetAnnotations()[hardwareDriftAnnotation]; !ok {
+		return false
+	}
+	end := host.Status.OperationHistory.Inspect.End
+	if end.IsZero() || host.GetAnnotations()[inspectionTimestampAnnotation] == end.UTC().Format(time.RFC3339) {
+		return false
+	}
+	return deleteAnnotation(host, hardwareDriftAnnotation)
+}
+
+// hostDrift returns the drift recorded on the host, if any.
+func hostDrift(host *bmh.BareMetalHost) *hardwareDrift {
+	data, ok := host.GetAnnotations()[hardwareDriftAnnotation]
+	if !ok {
+		return nil
+	}
+	drift := &hardwareDrift{}
+	if err := json.Unmarshal([]byte(data), drift); err != nil {
+		return nil
+	}
+	return drift
+}
+
+// snapshotHardware records the hardware of the host so later changes
+// can be detected. Only hosts which matched a profile at some point
+// get a snapshot.
+func snapshotHardware(host *bmh.BareMetalHost) bool {
+	_, hasSnapshot := host.GetAnnotations()[hardwareHashAnnotation]
+	if !hasSnapshot && len(labelledProfiles(host)) == 0 {
+		return false
+	}
+
+	facts, err := json.Marshal(classifier.HostFacts(host))
+	if err != nil {
+		return false
+	}
+	changed := setAnnotation(host, hardwareHashAnnotation, classifier.HardwareHash(host))
+	changed = setAnnotation(host, hardwareSnapshotAnnotation, string(facts)) || changed
+	return changed
+}
+
+// setDriftLabel sets the drift label on hosts with a recorded drift
+// and removes it from the others.
+func setDriftLabel(host *bmh.BareMetalHost, enabled bool) bool {
+	if enabled && hostDrift(host) != nil {
+		return setLabel(host, hardwareDriftLabel, "true")
+	}
+	return deleteLabel(host, hardwareDriftLabel)
+}
+
+// labelledProfiles returns the names of the profiles and cluster
+// profiles the host is labelled for. Cluster profiles are named
+// ClusterHardwareClassification/<name>, as in the coverage reports.
+func labelledProfiles(host *bmh.BareMetalHost) []string {
+	profiles := []string{}
+	for key := range host.GetLabels() {
+		switch {
+		case strings.HasPrefix(key, defaultLabelName) && key != unclassifiedLabel:
+			profiles = append(profiles, strings.TrimPrefix(key, defaultLabelName))
+		case strings.HasPrefix(key, clusterLabelName):
+			profiles = append(profiles, clusterProfilePrefix+strings.TrimPrefix(key, clusterLabelName))
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// setDriftCondition reports the hosts whose hardware changed while
+// they were labelled for the profile, or for the cluster profile when
+// it has no namespace.
+func setDriftCondition(hwc *hwcc.HardwareClassification, hosts []bmh.BareMetalHost) {
+	name := hwc.Name
+	if hwc.Namespace == "" {
+		name = clusterProfilePrefix + name
+	}
+	drifted := []string{}
+	for i := range hosts {
+		drift := hostDrift(&hosts[i])
+		if drift != nil && utils.StringInList(drift.Profiles, name) {
+			drifted = append(drifted, hosts[i].Name)
+		}
+	}
+	sort.Strings(drifted)
+
+	condition := metav1.Condition{
+		Type:               hwcc.ConditionHardwareDrift,
+		Status:             metav1.ConditionFalse,
+		Reason:             hwcc.ReasonNoHardwareDrift,
+		ObservedGeneration: hwc.Generation,
+		Message:            "no hardware changes detected",
+	}
+	if len(drifted) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = hwcc.ReasonHardwareChanged
+		condition.Message = fmt.Sprintf("hardware changed on hosts: %s",
+			strings.Join(drifted, ", "))
+	}
+	meta.SetStatusCondition(&hwc.Status.Conditions, condition)
+}
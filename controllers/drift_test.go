@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+)
+
+func newDriftHost() *bmh.BareMetalHost {
+	return &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "host",
+			Namespace: "namespace",
+		},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				RAMMebibytes: 64 * 1024,
+				Storage:      []bmh.Storage{{Name: "sda"}, {Name: "sdb"}},
+			},
+		},
+	}
+}
+
+func TestSnapshotHardware(t *testing.T) {
+	host := newDriftHost()
+
+	assert.False(t, snapshotHardware(host), "unlabelled hosts get no snapshot")
+	assert.Nil(t, detectDrift(host))
+
+	host.Labels = map[string]string{defaultLabelName + "profile": defaultLabelValue}
+	assert.True(t, snapshotHardware(host))
+	assert.False(t, snapshotHardware(host))
+	assert.Nil(t, detectDrift(host))
+}
+
+func TestDetectDrift(t *testing.T) {
+	host := newDriftHost()
+	host.Labels = map[string]string{defaultLabelName + "profile": defaultLabelValue}
+	snapshotHardware(host)
+
+	host.Status.HardwareDetails.RAMMebibytes = 48 * 1024
+	host.Status.HardwareDetails.Storage = host.Status.HardwareDetails.Storage[:1]
+
+	drift := detectDrift(host)
+	if assert.NotNil(t, drift) {
+		assert.Equal(t, []string{"profile"}, drift.Profiles)
+		assert.Equal(t, []classifier.FactChange{
+			{Fact: classifier.FactDiskCount, Old: "2", New: "1"},
+			{Fact: classifier.FactRAMGB, Old: "64", New: "48"},
+		}, drift.Changes)
+		assert.Equal(t, `disk-count "2" -> "1", ram-gb "64" -> "48"`, drift.String())
+	}
+
+	assert.True(t, recordDrift(host, drift))
+	assert.Equal(t, drift, hostDrift(host))
+
+	assert.True(t, snapshotHardware(host))
+	assert.Nil(t, detectDrift(host), "the new hardware becomes the reference")
+	assert.NotNil(t, hostDrift(host), "the drift is kept until removed")
+}
+
+func TestDetectDriftLegacyHash(t *testing.T) {
+	host := newDriftHost()
+	host.Labels = map[string]string{defaultLabelName + "profile": defaultLabelValue}
+	host.Annotations = map[string]string{hardwareHashAnnotation: "0123456789abcdef"}
+
+	// The snapshot of an earlier version is replaced without a drift.
+	assert.Nil(t, detectDrift(host))
+	assert.True(t, snapshotHardware(host))
+	assert.Equal(t, classifier.HardwareHash(host), host.Annotations[hardwareHashAnnotation])
+}
+
+func TestClearConfirmedDrift(t *testing.T) {
+	host := newDriftHost()
+	assert.False(t, clearConfirmedDrift(host))
+
+	start := metav1.NewTime(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+	host.Status.OperationHistory.Inspect = bmh.OperationMetric{Start: start, End: metav1.NewTime(start.Add(5 * time.Minute))}
+	recordDrift(host, &hardwareDrift{Profiles: []string{"profile"}})
+	setInspectionTimestamp(host)
+	assert.False(t, clearConfirmedDrift(host), "the inspection which detected the drift")
+	assert.NotNil(t, hostDrift(host))
+	assert.True(t, setDriftLabel(host, true))
+
+	start = metav1.NewTime(start.Add(time.Hour))
+	host.Status.OperationHistory.Inspect = bmh.OperationMetric{Start: start, End: metav1.NewTime(start.Add(5 * time.Minute))}
+	assert.True(t, clearConfirmedDrift(host), "a later inspection")
+	assert.Nil(t, hostDrift(host))
+	assert.True(t, setDriftLabel(host, true))
+	assert.NotContains(t, host.Labels, hardwareDriftLabel)
+
+	// Hosts without inspection history keep the drift.
+	host = newDriftHost()
+	recordDrift(host, &hardwareDrift{Profiles: []string{"profile"}})
+	assert.False(t, clearConfirmedDrift(host))
+}
+
+func TestSetDriftLabel(t *testing.T) {
+	host := newDriftHost()
+	assert.False(t, setDriftLabel(host, true))
+
+	recordDrift(host, &hardwareDrift{Profiles: []string{"profile"}})
+	assert.False(t, setDriftLabel(host, false))
+	assert.True(t, setDriftLabel(host, true))
+	assert.Equal(t, "true", host.Labels[hardwareDriftLabel])
+
+	delete(host.Annotations, hardwareDriftAnnotation)
+	assert.True(t, setDriftLabel(host, true))
+	assert.NotContains(t, host.Labels, hardwareDriftLabel)
+}
+
+func TestSetDriftCondition(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "namespace"},
+	}
+	hosts := []bmh.BareMetalHost{*newDriftHost(), *newDriftHost()}
+	hosts[1].Name = "other-profile"
+	recordDrift(&hosts[1], &hardwareDrift{Profiles: []string{"other"}})
+
+	setDriftCondition(&profile, hosts)
+	assert.True(t, meta.IsStatusConditionFalse(profile.Status.Conditions, hwcc.ConditionHardwareDrift))
+
+	recordDrift(&hosts[0], &hardwareDrift{Profiles: []string{"profile"}})
+	setDriftCondition(&profile, hosts)
+	condition := meta.FindStatusCondition(profile.Status.Conditions, hwcc.ConditionHardwareDrift)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, hwcc.ReasonHardwareChanged, condition.Reason)
+		assert.Equal(t, "hardware changed on hosts: host", condition.Message)
+	}
+}
+
+func TestDetectClusterProfileDrift(t *testing.T) {
+	// The host is only labelled for a cluster profile.
+	host := newDriftHost()
+	host.Labels = map[string]string{clusterLabelName + "large": defaultLabelValue}
+	assert.Equal(t, []string{clusterProfilePrefix + "large"}, labelledProfiles(host))
+	assert.True(t, snapshotHardware(host))
+
+	host.Status.HardwareDetails.RAMMebibytes = 48 * 1024
+	drift := detectDrift(host)
+	if assert.NotNil(t, drift) {
+		assert.Equal(t, []string{clusterProfilePrefix + "large"}, drift.Profiles)
+	}
+	recordDrift(host, drift)
+
+	// The drift is reported by the cluster profile, not by a namespaced
+	// profile of the same name.
+	clusterProfile := profileFromCluster(&hwcc.ClusterHardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large"},
+	})
+	setDriftCondition(clusterProfile, []bmh.BareMetalHost{*host})
+	assert.True(t, meta.IsStatusConditionTrue(clusterProfile.Status.Conditions, hwcc.ConditionHardwareDrift))
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: host.Namespace},
+	}
+	setDriftCondition(profile, []bmh.BareMetalHost{*host})
+	assert.True(t, meta.IsStatusConditionFalse(profile.Status.Conditions, hwcc.ConditionHardwareDrift))
+
+	// Cluster profiles are not enqueued as namespaced profiles.
+	mapper := labelledClassificationMapper{}
+	assert.Empty(t, mapper.Map(handler.MapObject{Meta: host, Object: host}))
+}
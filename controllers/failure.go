@@ -0,0 +1,84 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+// DefaultFailureLabel is the label set on hosts in error when no other
+// label key is configured. Its value is the error type of the host.
+const DefaultFailureLabel = "hardwareclassification-error"
+
+// failureLabelKey returns the configured failure label key.
+func (r *BareMetalHostReconciler) failureLabelKey() string {
+	if r.FailureLabel == "" {
+		return DefaultFailureLabel
+	}
+	return r.FailureLabel
+}
+
+// hostFailed reports whether the host is in error. The failed hosts
+// are counted apart in the status of the profiles.
+func hostFailed(host *bmh.BareMetalHost) bool {
+	return host.Status.OperationalStatus == bmh.OperationalStatusError
+}
+
+// failureLabelValue returns the value of the failure label for the
+// host, or an empty string when the host is not in error.
+func failureLabelValue(host *bmh.BareMetalHost) string {
+	if !hostFailed(host) {
+		return ""
+	}
+	value := strings.ReplaceAll(string(host.Status.ErrorType), " ", "-")
+	if value == "" {
+		// Hosts may be in error before the error type is set.
+		value = string(bmh.OperationalStatusError)
+	}
+	return value
+}
+
+// setFailureLabel sets the failure label on the host in error and
+// removes it once the host recovered, and reports whether the host
+// changed. The label belongs to the host, whatever the profiles it
+// matches.
+func (r *BareMetalHostReconciler) setFailureLabel(logger logr.Logger, host *bmh.BareMetalHost) bool {
+	labelKey := r.failureLabelKey()
+	var changed bool
+	if value := failureLabelValue(host); value != "" {
+		changed = setLabel(host, labelKey, value)
+	} else {
+		changed = deleteLabel(host, labelKey)
+	}
+	if changed {
+		logger.Info("updated failure label", "label", labelKey, "value", host.Labels[labelKey])
+	}
+	return changed
+}
+
+// labelFailedHost only updates the failure label of the host, for the
+// hosts which are not classified.
+func (r *BareMetalHostReconciler) labelFailedHost(ctx context.Context, logger logr.Logger, host *bmh.BareMetalHost) error {
+	_, err := patchHost(ctx, r, host, func(host *bmh.BareMetalHost) (bool, error) {
+		return r.setFailureLabel(logger, host), nil
+	})
+	return errors.Wrapf(err, "failed to update failure label of host %s/%s", host.Namespace, host.Name)
+}
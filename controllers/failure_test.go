@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func failureHost(name string, status bmh.OperationalStatus, errorType bmh.ErrorType, labels map[string]string) *bmh.BareMetalHost {
+	return &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "metal3",
+			Labels:    labels,
+		},
+		Status: bmh.BareMetalHostStatus{
+			OperationalStatus: status,
+			ErrorType:         errorType,
+		},
+	}
+}
+
+func TestFailureLabelValue(t *testing.T) {
+	assert.Equal(t, "", failureLabelValue(failureHost("host", bmh.OperationalStatusOK, "", nil)))
+	assert.Equal(t, "registration-error",
+		failureLabelValue(failureHost("host", bmh.OperationalStatusError, bmh.RegistrationError, nil)))
+	assert.Equal(t, "error", failureLabelValue(failureHost("host", bmh.OperationalStatusError, "", nil)))
+}
+
+func TestReconcileFailureLabel(t *testing.T) {
+	testCases := []struct {
+		Scenario      string
+		FailureLabel  string
+		Host          *bmh.BareMetalHost
+		ExpectedKey   string
+		ExpectedValue string
+	}{
+		{
+			Scenario:      "failed host",
+			Host:          failureHost("host", bmh.OperationalStatusError, bmh.InspectionError, nil),
+			ExpectedKey:   DefaultFailureLabel,
+			ExpectedValue: "inspection-error",
+		},
+		{
+			Scenario:      "already labelled",
+			Host:          failureHost("host", bmh.OperationalStatusError, bmh.InspectionError, map[string]string{DefaultFailureLabel: "inspection-error"}),
+			ExpectedKey:   DefaultFailureLabel,
+			ExpectedValue: "inspection-error",
+		},
+		{
+			Scenario:      "error type changed",
+			Host:          failureHost("host", bmh.OperationalStatusError, bmh.ProvisioningError, map[string]string{DefaultFailureLabel: "inspection-error"}),
+			ExpectedKey:   DefaultFailureLabel,
+			ExpectedValue: "provisioning-error",
+		},
+		{
+			Scenario:    "recovered host",
+			Host:        failureHost("host", bmh.OperationalStatusOK, "", map[string]string{DefaultFailureLabel: "inspection-error", "other": "kept"}),
+			ExpectedKey: DefaultFailureLabel,
+		},
+		{
+			Scenario:      "custom label",
+			FailureLabel:  "example.com/error",
+			Host:          failureHost("host", bmh.OperationalStatusError, bmh.PowerManagementError, nil),
+			ExpectedKey:   "example.com/error",
+			ExpectedValue: "power-management-error",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NoError(t, hwcc.AddToScheme(scheme))
+			assert.NoError(t, bmh.AddToScheme(scheme))
+			r := &BareMetalHostReconciler{
+				Client:       fake.NewFakeClientWithScheme(scheme, tc.Host.DeepCopy()),
+				Log:          ctrl.Log.WithName("test"),
+				Scheme:       scheme,
+				Recorder:     record.NewFakeRecorder(10),
+				FailureLabel: tc.FailureLabel,
+			}
+			key := types.NamespacedName{Name: "host", Namespace: "metal3"}
+
+			_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			assert.NoError(t, err)
+
+			host := &bmh.BareMetalHost{}
+			assert.NoError(t, r.Get(context.TODO(), key, host))
+			value, ok := host.Labels[tc.ExpectedKey]
+			assert.Equal(t, tc.ExpectedValue != "", ok)
+			assert.Equal(t, tc.ExpectedValue, value)
+			for key, value := range tc.Host.Labels {
+				if key != tc.ExpectedKey {
+					assert.Equal(t, value, host.Labels[key])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileFailureLabelClassified(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+	host := failureHost("host", bmh.OperationalStatusError, bmh.ProvisioningError, nil)
+	host.Status.HardwareDetails = &bmh.HardwareDetails{CPU: bmh.CPU{Count: 32}}
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "metal3"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 16},
+			},
+		},
+	}
+	c := &conflictClient{Client: fake.NewFakeClientWithScheme(scheme, host, profile)}
+	r := &BareMetalHostReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	key := types.NamespacedName{Name: "host", Namespace: "metal3"}
+
+	// The failure label is set along with the labels of the profiles,
+	// in a single patch of the host.
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Len(t, c.patches, 1)
+	assert.NoError(t, c.Get(context.TODO(), key, host))
+	assert.Equal(t, "provisioning-error", host.Labels[DefaultFailureLabel])
+	assert.Contains(t, host.Labels, defaultLabelName+"large")
+}
+
+func TestFailedHostsAgree(t *testing.T) {
+	labelKey := defaultLabelName + "profile"
+	inspected := failureHost("inspected", bmh.OperationalStatusError, bmh.ProvisioningError,
+		map[string]string{labelKey: defaultLabelValue})
+	inspected.Status.HardwareDetails = &bmh.HardwareDetails{}
+	hosts := []bmh.BareMetalHost{
+		*inspected,
+		*failureHost("uninspected", bmh.OperationalStatusError, bmh.InspectionError, nil),
+		*failureHost("unmatched", bmh.OperationalStatusOK, "", nil),
+	}
+
+	// The failed hosts are the hosts given a failure label, with or
+	// without hardware details.
+	failed := fetchFailedBmhHostList(bmh.BareMetalHostList{Items: hosts})
+	names := []string{}
+	for i := range failed {
+		names = append(names, failed[i].Name)
+	}
+	assert.Equal(t, []string{"inspected", "uninspected"}, names)
+	for i := range hosts {
+		assert.Equal(t, hostFailed(&hosts[i]), failureLabelValue(&hosts[i]) != "", hosts[i].Name)
+	}
+	assert.Equal(t, "provisioning-error", failureLabelValue(inspected))
+
+	// The labelled host in error is not counted twice.
+	hwc := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "metal3"},
+	}
+	setStatus(hwc, labelKey, hosts, failed, 1, nil)
+	assert.Equal(t, hwcc.MatchedCount(1), hwc.Status.MatchedCount)
+	assert.Equal(t, hwcc.UnmatchedCount(1), hwc.Status.UnmatchedCount)
+	assert.Equal(t, hwcc.ErrorHosts(2), hwc.Status.ErrorHosts)
+}
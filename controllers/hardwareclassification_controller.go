@@ -18,19 +18,26 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
 	"github.com/metal3-io/hardware-classification-controller/utils"
 	"github.com/pkg/errors"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -38,7 +45,6 @@ import (
 const (
 	//HWControllerName Name to show in the logs
 	HWControllerName = "HardwareClassification-Controller"
-	failedLabelName  = "hardwareclassification-error"
 )
 
 // HardwareClassificationReconciler reconciles a HardwareClassification object
@@ -65,6 +71,10 @@ func (hcReconciler *HardwareClassificationReconciler) Reconcile(req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	if reservedProfileName(hardwareClassification) {
+		return ctrl.Result{}, hcReconciler.rejectProfile(ctx, hwcLog, hardwareClassification)
+	}
+
 	// Add a finalizer to newly created objects.
 	if hardwareClassification.DeletionTimestamp.IsZero() && !hasFinalizer(hardwareClassification) {
 		hwcLog.Info(
@@ -103,7 +113,7 @@ func (hcReconciler *HardwareClassificationReconciler) Reconcile(req ctrl.Request
 		if _, ok := labels[labelKey]; !ok {
 			continue
 		}
-		hwcLog.Info("found host with label",
+		hwcLog.V(1).Info("found host with label",
 			"host", host.Name,
 			"label", labelKey,
 		)
@@ -130,89 +140,268 @@ func (hcReconciler *HardwareClassificationReconciler) Reconcile(req ctrl.Request
 		if err != nil {
 			return ctrl.Result{}, errors.Wrap(err, "failed to remove finalizer")
 		}
+		classifier.DefaultCache.ForgetProfile(hardwareClassification.UID)
 
 		hwcLog.Info("deleting")
 		return ctrl.Result{}, nil
 	}
 
+	// Classify with the characteristics merged from the template, the
+	// spec itself is never written back.
+	templateErr := resolveTemplate(ctx, hcReconciler, hardwareClassification)
+	if templateErr != nil {
+		hwcLog.Error(templateErr, "could not resolve template")
+	}
+
 	failedHostList := fetchFailedBmhHostList(bmhHostList)
-	if len(failedHostList) > 0 {
-		if changed := labelFailedHost(hcReconciler, failedHostList, ctx); changed != false {
-			hwcLog.Info("set label ", "failed host list", failedHostList)
+
+	// The whole status is computed before writing it once, and only
+	// when it changed.
+	original := hardwareClassification.DeepCopy()
+	setStatus(hardwareClassification, labelKey, bmhHostList.Items, failedHostList, matchCount, templateErr)
+	if !equality.Semantic.DeepEqual(original.Status, hardwareClassification.Status) {
+		hwcLog.Info("updating status",
+			"matchStatus", hardwareClassification.Status.ProfileMatchStatus,
+			"matched", hardwareClassification.Status.MatchedCount,
+			"unmatched", hardwareClassification.Status.UnmatchedCount,
+			"errors", hardwareClassification.Status.ErrorHosts,
+		)
+		err = hcReconciler.Status().Patch(ctx, hardwareClassification, client.MergeFrom(original))
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to update status")
 		}
 	}
 
-	// Update our status to report whether we have matched a host or not.
+	return ctrl.Result{}, nil
+}
+
+// setStatus computes the status of the profile, labelling hosts with
+// labelKey, from the hosts it applies to. The parts of the status
+// depending on the hardware characteristics of the profile are left
+// alone while its template cannot be resolved.
+func setStatus(hwc *hwcc.HardwareClassification, labelKey string, hosts, failedHosts []bmh.BareMetalHost, matchCount int, templateErr error) {
+	// Report whether we have matched a host or not.
 	status := hwcc.ProfileMatchStatusMatched
 	if matchCount == 0 {
 		status = hwcc.ProfileMatchStatusUnMatched
 	}
-	if len(bmhHostList.Items) == 0 {
+	if len(hosts) == 0 {
 		status = hwcc.NoBareMetalHosts
 	}
-
-	if hardwareClassification.Status.ProfileMatchStatus != status {
-		hwcLog.Info("updating match status", "newValue", status)
-		hardwareClassification.Status.ProfileMatchStatus = status
-		err = hcReconciler.Status().Update(context.TODO(), hardwareClassification)
-		if err != nil {
-			return ctrl.Result{}, errors.Wrap(err, "failed to update status")
-		}
-		return ctrl.Result{}, nil
+	hwc.Status.ProfileMatchStatus = status
+
+	setHostCount(hwc, hwcc.MatchedCount(matchCount), hwcc.UnmatchedCount(unmatchedCount(hosts, labelKey)))
+	setErrHostCount(hwc, failedHosts)
+	setMatchedHostStates(hwc, hosts, labelKey)
+	setTemplateCondition(hwc, templateErr)
+	setDriftCondition(hwc, hosts)
+	if templateErr != nil {
+		return
 	}
+	setHostScores(hwc, hosts)
+	setCapacity(hwc, labelKey, hosts, matchCount)
+	setStaleHosts(hwc, hosts, labelKey)
+}
 
-	setHostCount(hardwareClassification, hwcc.MatchedCount(matchCount), hwcc.UnmatchedCount(len(bmhHostList.Items)-(len(failedHostList)+matchCount)))
-	setErrHostCount(hardwareClassification, failedHostList)
-	err = hcReconciler.Status().Update(context.TODO(), hardwareClassification)
-	if err != nil {
-		return ctrl.Result{}, errors.Wrap(err, "failed to update status")
+// unmatchedCount counts the hosts which are neither labelled for the
+// profile nor in error. Failed hosts are counted apart, whether they
+// matched before failing or not.
+func unmatchedCount(hosts []bmh.BareMetalHost, labelKey string) int {
+	count := 0
+	for i := range hosts {
+		if !hasLabel(&hosts[i], labelKey) && !hostFailed(&hosts[i]) {
+			count++
+		}
 	}
-
-	return ctrl.Result{}, nil
+	return count
 }
 
 func setHostCount(hwc *hwcc.HardwareClassification, MatchedHost hwcc.MatchedCount, UnmatchedHost hwcc.UnmatchedCount) {
 	hwc.Status.MatchedCount = MatchedHost
-	fmt.Println("Updating Matched host count")
 	hwc.Status.UnmatchedCount = UnmatchedHost
-	fmt.Println("Updating Unmatched host count")
 }
 
+// Error types without a constant in the baremetal-operator version the
+// controller is built with, but counted in the status.
+const (
+	provisionedRegistrationError bmh.ErrorType = "provisioned registration error"
+	preparationError             bmh.ErrorType = "preparation error"
+	detachError                  bmh.ErrorType = "detach error"
+)
+
+// setErrHostCount counts the hosts in error state by error type and
+// lists the first of them. The counters of the known error types are
+// kept for existing clients.
 func setErrHostCount(hwc *hwcc.HardwareClassification, failedHosts []bmh.BareMetalHost) {
-	registrationErrorCount := 0
-	introspectionErrorCount := 0
-	provisioningErrorCount := 0
-	powerMgmtErrorCount := 0
-	proviRegisErrorCount := 0
-	preprationErrorCount := 0
-	detachErrorCount := 0
+	byType := map[string]int{}
+	failing := []hwcc.FailingHost{}
 	for _, host := range failedHosts {
-		switch host.Status.ErrorType {
-		case "registration error":
-			registrationErrorCount += 1
-		case "inspection error":
-			introspectionErrorCount += 1
-		case "provisioning error":
-			provisioningErrorCount += 1
-		case "power management error":
-			powerMgmtErrorCount += 1
-		case "provisioned registration error":
-			proviRegisErrorCount += 1
-		case "preparation error":
-			preprationErrorCount += 1
-		case "detach error":
-			detachErrorCount += 1
+		errorType := string(host.Status.ErrorType)
+		if errorType == "" {
+			errorType = hwcc.UnknownErrorType
 		}
+		byType[errorType]++
+		failing = append(failing, hwcc.FailingHost{
+			Name:         host.Name,
+			ErrorType:    string(host.Status.ErrorType),
+			ErrorMessage: host.Status.ErrorMessage,
+		})
+	}
+	sort.Slice(failing, func(i, j int) bool { return failing[i].Name < failing[j].Name })
+	if len(failing) > hwcc.MaxFailingHosts {
+		failing = failing[:hwcc.MaxFailingHosts]
+	}
+	if len(failing) == 0 {
+		byType = nil
+		failing = nil
 	}
-	fmt.Println("Updating ErrorHost count")
+
 	hwc.Status.ErrorHosts = hwcc.ErrorHosts(len(failedHosts))
-	hwc.Status.RegistrationErrorHosts = hwcc.RegistrationErrorHosts(registrationErrorCount)
-	hwc.Status.IntrospectionErrorHosts = hwcc.IntrospectionErrorHosts(introspectionErrorCount)
-	hwc.Status.ProvisioningErrorHosts = hwcc.ProvisioningErrorHosts(provisioningErrorCount)
-	hwc.Status.PowerMgmtErrorHosts = hwcc.PowerMgmtErrorHosts(powerMgmtErrorCount)
-	hwc.Status.ProvisionedRegistrationErrorHosts = hwcc.ProvisionedRegistrationErrorHosts(proviRegisErrorCount)
-	hwc.Status.PreparationErrorHosts = hwcc.PreparationErrorHosts(preprationErrorCount)
-	hwc.Status.DetachErrorHosts = hwcc.DetachErrorHosts(detachErrorCount)
+	hwc.Status.ErrorHostsByType = byType
+	hwc.Status.FailingHosts = failing
+	hwc.Status.RegistrationErrorHosts = hwcc.RegistrationErrorHosts(byType[string(bmh.RegistrationError)])
+	hwc.Status.IntrospectionErrorHosts = hwcc.IntrospectionErrorHosts(byType[string(bmh.InspectionError)])
+	hwc.Status.ProvisioningErrorHosts = hwcc.ProvisioningErrorHosts(byType[string(bmh.ProvisioningError)])
+	hwc.Status.PowerMgmtErrorHosts = hwcc.PowerMgmtErrorHosts(byType[string(bmh.PowerManagementError)])
+	hwc.Status.ProvisionedRegistrationErrorHosts = hwcc.ProvisionedRegistrationErrorHosts(byType[string(provisionedRegistrationError)])
+	hwc.Status.PreparationErrorHosts = hwcc.PreparationErrorHosts(byType[string(preparationError)])
+	hwc.Status.DetachErrorHosts = hwcc.DetachErrorHosts(byType[string(detachError)])
+}
+
+// setHostScores records the best scoring hosts in the status of
+// profiles using score based classification.
+func setHostScores(hwc *hwcc.HardwareClassification, hosts []bmh.BareMetalHost) {
+	if hwc.Spec.Scoring == nil {
+		hwc.Status.HostScores = nil
+		return
+	}
+
+	scores := []hwcc.HostScore{}
+	for i := range hosts {
+		if hosts[i].Status.HardwareDetails == nil {
+			continue
+		}
+		scores = append(scores, hwcc.HostScore{
+			Name:  hosts[i].Name,
+			Score: classifier.DefaultCache.ProfileScoreForHost(hwc, &hosts[i]),
+		})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Name < scores[j].Name
+	})
+	if len(scores) > hwcc.MaxHostScores {
+		scores = scores[:hwcc.MaxHostScores]
+	}
+	hwc.Status.HostScores = scores
+}
+
+// setCapacity records how many hosts match the profile compared to
+// how many are labelled, which hosts are selected when the profile
+// limits the number of hosts, and whether the minimum number of hosts
+// is available. A change of the selected hosts enqueues them, and the
+// hosts labelled for the profile, in the BareMetalHost reconciler.
+func setCapacity(hwc *hwcc.HardwareClassification, labelKey string, hosts []bmh.BareMetalHost, labelled int) {
+	eligible := eligibleHosts(hwc, labelKey, hosts)
+	hwc.Status.EligibleCount = len(eligible)
+	hwc.Status.LabelledCount = labelled
+
+	hwc.Status.SelectedHosts = nil
+	if hwc.Spec.MaxHosts > 0 {
+		hwc.Status.SelectedHosts = selectHosts(hwc, eligible)
+	}
+
+	if hwc.Spec.MinHosts == 0 {
+		removeStatusCondition(&hwc.Status.Conditions, hwcc.ConditionMinHostsAvailable)
+		return
+	}
+	condition := metav1.Condition{
+		Type:               hwcc.ConditionMinHostsAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             hwcc.ReasonEnoughHosts,
+		ObservedGeneration: hwc.Generation,
+		Message: fmt.Sprintf("%d of at least %d hosts match the profile",
+			len(eligible), hwc.Spec.MinHosts),
+	}
+	if len(eligible) < hwc.Spec.MinHosts {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = hwcc.ReasonInsufficientHosts
+	}
+	meta.SetStatusCondition(&hwc.Status.Conditions, condition)
+}
+
+// removeStatusCondition removes the condition if present. The
+// apimachinery helper panics when the list of conditions is empty.
+func removeStatusCondition(conditions *[]metav1.Condition, conditionType string) {
+	if meta.FindStatusCondition(*conditions, conditionType) == nil {
+		return
+	}
+	meta.RemoveStatusCondition(conditions, conditionType)
+}
+
+// setStaleHosts records the hosts which are still labelled for the
+// profile although they no longer match it, which happens when the
+// unlabel policy keeps the label.
+func setStaleHosts(hwc *hwcc.HardwareClassification, hosts []bmh.BareMetalHost, labelKey string) {
+	stale := []string{}
+	for i := range hosts {
+		if !hasLabel(&hosts[i], labelKey) || hosts[i].Status.HardwareDetails == nil {
+			continue
+		}
+		if !classifier.DefaultCache.ProfileMatchesHost(hwc, &hosts[i]) {
+			stale = append(stale, hosts[i].Name)
+		}
+	}
+	sort.Strings(stale)
+	if len(stale) == 0 {
+		stale = nil
+	}
+	hwc.Status.StaleHosts = stale
+}
+
+// setMatchedHostStates breaks the hosts labelled for the profile down
+// by provisioning state and counts how many of them are consumed and
+// how many are still free to use.
+func setMatchedHostStates(hwc *hwcc.HardwareClassification, hosts []bmh.BareMetalHost, labelKey string) {
+	byState := map[string]int{}
+	consumed := 0
+	available := 0
+	for i := range hosts {
+		if !hasLabel(&hosts[i], labelKey) {
+			continue
+		}
+		state := string(hosts[i].Status.Provisioning.State)
+		if state == "" {
+			state = hwcc.NoProvisioningState
+		}
+		byState[state]++
+
+		switch {
+		case hosts[i].Spec.ConsumerRef != nil:
+			consumed++
+		case hostAvailable(&hosts[i]):
+			available++
+		}
+	}
+	if len(byState) == 0 {
+		byState = nil
+	}
+	hwc.Status.MatchedHostsByState = byState
+	hwc.Status.ConsumedCount = consumed
+	hwc.Status.AvailableCount = available
+}
+
+// hostAvailable reports whether the host can be provisioned.
+func hostAvailable(host *bmh.BareMetalHost) bool {
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		return host.Spec.ConsumerRef == nil
+	default:
+		return false
+	}
 }
 
 func hasFinalizer(profile *hwcc.HardwareClassification) bool {
@@ -220,47 +409,39 @@ func hasFinalizer(profile *hwcc.HardwareClassification) bool {
 }
 
 // SetupWithManager will add watches for this controller
-func (hcReconciler *HardwareClassificationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (hcReconciler *HardwareClassificationReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 
 	mapper := classificationMapper{
 		client: mgr.GetClient(),
 	}
+	labelledMapper := labelledClassificationMapper{}
+	templateMapper := templateClassificationMapper{
+		client: mgr.GetClient(),
+	}
 
+	// Most host updates only concern the profiles the host is
+	// labelled for, every profile of the namespace is only enqueued
+	// when the hardware or error state of a host changes.
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hwcc.HardwareClassification{}).
 		Named("hardware-classification").
 		Watches(&source.Kind{Type: &bmh.BareMetalHost{}},
-			&handler.EnqueueRequestsFromMapFunc{ToRequests: &mapper}).
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &labelledMapper},
+			builder.WithPredicates(hostMatchChanged)).
+		Watches(&source.Kind{Type: &bmh.BareMetalHost{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &mapper},
+			builder.WithPredicates(hostInventoryChanged)).
+		Watches(&source.Kind{Type: &hwcc.HardwareClassificationTemplate{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &templateMapper},
+			builder.WithPredicates(profileSpecChanged)).
+		WithOptions(options).
 		Complete(hcReconciler)
 }
 
-func labelFailedHost(hcReconciler *HardwareClassificationReconciler, failedHostList []bmh.BareMetalHost, ctx context.Context) bool {
-	for _, host := range failedHostList {
-		labels := host.GetLabels()
-		if labels == nil {
-			labels = make(map[string]string)
-		}
-		labelValue := strings.ReplaceAll(string(host.Status.ErrorType), " ", "-")
-		// If we already have a label with the same value no change is
-		// needed.
-		if val, ok := labels[failedLabelName]; ok {
-			if val == labelValue {
-				return false
-			}
-		}
-		labels[failedLabelName] = labelValue
-		host.SetLabels(labels)
-		if err := hcReconciler.Client.Update(ctx, &host); err != nil {
-			return false
-		}
-	}
-	return true
-}
-
 func fetchFailedBmhHostList(bmhHostList bmh.BareMetalHostList) (failedHostList []bmh.BareMetalHost) {
 	// Get hosts in error status from bmhHostList
 	for _, host := range bmhHostList.Items {
-		if host.Status.HardwareDetails == nil && host.Status.OperationalStatus == "error" {
+		if hostFailed(&host) {
 			failedHostList = append(failedHostList, host)
 		}
 	}
@@ -290,7 +471,7 @@ func (m *classificationMapper) Map(obj handler.MapObject) []ctrl.Request {
 
 	requests := []ctrl.Request{}
 	for _, profile := range hwcList.Items {
-		log.Info("found hardwareclassification", "name", profile.Name)
+		log.V(1).Info("found hardwareclassification", "name", profile.Name)
 		requests = append(requests, ctrl.Request{
 			NamespacedName: types.NamespacedName{
 				Name:      profile.Name,
@@ -300,3 +481,29 @@ func (m *classificationMapper) Map(obj handler.MapObject) []ctrl.Request {
 	}
 	return requests
 }
+
+// labelledClassificationMapper enqueues the profiles a host is
+// labelled for. Handlers map both the old and the new host of an
+// update, so profiles losing the host are enqueued too.
+type labelledClassificationMapper struct{}
+
+func (m *labelledClassificationMapper) Map(obj handler.MapObject) []ctrl.Request {
+	host, ok := obj.Object.(*bmh.BareMetalHost)
+	if !ok {
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, name := range labelledProfiles(host) {
+		if strings.HasPrefix(name, clusterProfilePrefix) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      name,
+				Namespace: host.Namespace,
+			},
+		})
+	}
+	return requests
+}
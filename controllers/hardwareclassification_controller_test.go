@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func newHostWithCPUs(name string, count int) bmh.BareMetalHost {
+	return bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "namespace",
+		},
+		Status: bmh.BareMetalHostStatus{
+			HardwareDetails: &bmh.HardwareDetails{
+				CPU: bmh.CPU{Count: count},
+			},
+		},
+	}
+}
+
+func TestSetHostScores(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+				Nic: &hwcc.Nic{MinimumCount: 1},
+			},
+		},
+	}
+	hosts := []bmh.BareMetalHost{
+		newHostWithCPUs("small", 4),
+		newHostWithCPUs("large-b", 16),
+		newHostWithCPUs("large-a", 16),
+		{ObjectMeta: metav1.ObjectMeta{Name: "not-inspected"}},
+	}
+
+	setHostScores(&profile, hosts)
+	assert.Nil(t, profile.Status.HostScores)
+
+	profile.Spec.Scoring = &hwcc.Scoring{MinimumScore: 50}
+	setHostScores(&profile, hosts)
+	assert.Equal(t, []hwcc.HostScore{
+		{Name: "large-a", Score: 50},
+		{Name: "large-b", Score: 50},
+		{Name: "small", Score: 0},
+	}, profile.Status.HostScores)
+}
+
+func TestSetHostScoresLimit(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		Spec: hwcc.HardwareClassificationSpec{
+			Scoring: &hwcc.Scoring{},
+		},
+	}
+	hosts := []bmh.BareMetalHost{}
+	for i := 0; i < hwcc.MaxHostScores+5; i++ {
+		hosts = append(hosts, newHostWithCPUs(fmt.Sprintf("host-%02d", i), 1))
+	}
+
+	setHostScores(&profile, hosts)
+	assert.Len(t, profile.Status.HostScores, hwcc.MaxHostScores)
+	assert.Equal(t, "host-00", profile.Status.HostScores[0].Name)
+}
+
+func TestSetStaleHosts(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+			},
+		},
+	}
+	labelKey, _ := getLabelDetails(&profile)
+	hosts := []bmh.BareMetalHost{
+		newHostWithCPUs("small-labelled", 4),
+		newHostWithCPUs("small", 4),
+		newHostWithCPUs("large-labelled", 16),
+	}
+	hosts[0].Labels = map[string]string{labelKey: ""}
+	hosts[2].Labels = map[string]string{labelKey: ""}
+
+	setStaleHosts(&profile, hosts, labelKey)
+	assert.Equal(t, []string{"small-labelled"}, profile.Status.StaleHosts)
+
+	setStaleHosts(&profile, hosts[1:], labelKey)
+	assert.Nil(t, profile.Status.StaleHosts)
+}
+
+func TestSetStatus(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+			},
+		},
+	}
+	labelKey, _ := getLabelDetails(&profile)
+
+	small := newHostWithCPUs("small", 4)
+	small.Labels = map[string]string{labelKey: "matches"}
+	large := newHostWithCPUs("large", 16)
+	large.Labels = map[string]string{labelKey: "matches"}
+	failed := bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed"},
+		Status: bmh.BareMetalHostStatus{
+			OperationalStatus: bmh.OperationalStatusError,
+			ErrorType:         bmh.InspectionError,
+		},
+	}
+
+	testCases := []struct {
+		Scenario            string
+		Hosts               []bmh.BareMetalHost
+		MatchCount          int
+		TemplateErr         error
+		ExpectedMatchStatus hwcc.ProfileMatchStatus
+		ExpectedMatched     hwcc.MatchedCount
+		ExpectedUnmatched   hwcc.UnmatchedCount
+		ExpectedErrors      hwcc.ErrorHosts
+		ExpectedStale       []string
+	}{
+		{
+			Scenario:            "no hosts",
+			ExpectedMatchStatus: hwcc.NoBareMetalHosts,
+		},
+		{
+			Scenario:            "matched",
+			Hosts:               []bmh.BareMetalHost{small, large, failed},
+			MatchCount:          2,
+			ExpectedMatchStatus: hwcc.ProfileMatchStatusMatched,
+			ExpectedMatched:     2,
+			ExpectedErrors:      1,
+			ExpectedStale:       []string{"small"},
+		},
+		{
+			Scenario:            "unmatched",
+			Hosts:               []bmh.BareMetalHost{newHostWithCPUs("other", 4)},
+			ExpectedMatchStatus: hwcc.ProfileMatchStatusUnMatched,
+			ExpectedUnmatched:   1,
+		},
+		{
+			Scenario:            "template not found",
+			Hosts:               []bmh.BareMetalHost{small, large, failed},
+			MatchCount:          2,
+			TemplateErr:         errors.New("not found"),
+			ExpectedMatchStatus: hwcc.ProfileMatchStatusMatched,
+			ExpectedMatched:     2,
+			ExpectedErrors:      1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := profile.DeepCopy()
+			failedHosts := fetchFailedBmhHostList(bmh.BareMetalHostList{Items: tc.Hosts})
+
+			labelKey, _ := getLabelDetails(profile)
+			setStatus(profile, labelKey, tc.Hosts, failedHosts, tc.MatchCount, tc.TemplateErr)
+			assert.Equal(t, tc.ExpectedMatchStatus, profile.Status.ProfileMatchStatus)
+			assert.Equal(t, tc.ExpectedMatched, profile.Status.MatchedCount)
+			assert.Equal(t, tc.ExpectedUnmatched, profile.Status.UnmatchedCount)
+			assert.Equal(t, tc.ExpectedErrors, profile.Status.ErrorHosts)
+			assert.Equal(t, tc.ExpectedStale, profile.Status.StaleHosts)
+		})
+	}
+}
+
+func TestSetErrHostCount(t *testing.T) {
+	failedHost := func(name string, errorType bmh.ErrorType) bmh.BareMetalHost {
+		return bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: bmh.BareMetalHostStatus{
+				OperationalStatus: bmh.OperationalStatusError,
+				ErrorType:         errorType,
+				ErrorMessage:      string(errorType) + " on " + name,
+			},
+		}
+	}
+	hosts := []bmh.BareMetalHost{
+		failedHost("host-c", bmh.InspectionError),
+		failedHost("host-a", bmh.InspectionError),
+		failedHost("host-b", "servicing error"),
+		failedHost("host-d", ""),
+		failedHost("host-e", detachError),
+	}
+
+	profile := hwcc.HardwareClassification{}
+	setErrHostCount(&profile, hosts)
+	assert.Equal(t, hwcc.ErrorHosts(5), profile.Status.ErrorHosts)
+	assert.Equal(t, map[string]int{
+		"inspection error":    2,
+		"servicing error":     1,
+		"detach error":        1,
+		hwcc.UnknownErrorType: 1,
+	}, profile.Status.ErrorHostsByType)
+	assert.Equal(t, hwcc.IntrospectionErrorHosts(2), profile.Status.IntrospectionErrorHosts)
+	assert.Equal(t, hwcc.DetachErrorHosts(1), profile.Status.DetachErrorHosts)
+	assert.Equal(t, hwcc.RegistrationErrorHosts(0), profile.Status.RegistrationErrorHosts)
+	assert.Equal(t, hwcc.FailingHost{
+		Name:         "host-a",
+		ErrorType:    "inspection error",
+		ErrorMessage: "inspection error on host-a",
+	}, profile.Status.FailingHosts[0])
+	assert.Equal(t, "host-e", profile.Status.FailingHosts[4].Name)
+
+	for i := 0; i < hwcc.MaxFailingHosts; i++ {
+		hosts = append(hosts, failedHost(fmt.Sprintf("host-z%02d", i), bmh.ProvisioningError))
+	}
+	setErrHostCount(&profile, hosts)
+	assert.Len(t, profile.Status.FailingHosts, hwcc.MaxFailingHosts)
+	assert.Equal(t, hwcc.ProvisioningErrorHosts(hwcc.MaxFailingHosts), profile.Status.ProvisioningErrorHosts)
+
+	setErrHostCount(&profile, nil)
+	assert.Nil(t, profile.Status.ErrorHostsByType)
+	assert.Nil(t, profile.Status.FailingHosts)
+	assert.Equal(t, hwcc.ErrorHosts(0), profile.Status.ErrorHosts)
+}
+
+func TestSetMatchedHostStates(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile"},
+	}
+	labelKey, _ := getLabelDetails(&profile)
+	host := func(name string, state bmh.ProvisioningState, consumed, labelled bool) bmh.BareMetalHost {
+		host := newHostWithCPUs(name, 1)
+		host.Status.Provisioning.State = state
+		if consumed {
+			host.Spec.ConsumerRef = &corev1.ObjectReference{Name: "machine"}
+		}
+		if labelled {
+			host.Labels = map[string]string{labelKey: "matches"}
+		}
+		return host
+	}
+	hosts := []bmh.BareMetalHost{
+		host("ready", bmh.StateReady, false, true),
+		host("available", bmh.StateAvailable, false, true),
+		host("reserved", bmh.StateReady, true, true),
+		host("provisioned", bmh.StateProvisioned, true, true),
+		host("inspecting", bmh.StateInspecting, false, true),
+		host("new", bmh.StateNone, false, true),
+		host("not-labelled", bmh.StateReady, false, false),
+	}
+
+	setMatchedHostStates(&profile, hosts, labelKey)
+	assert.Equal(t, map[string]int{
+		"ready":       2,
+		"available":   1,
+		"provisioned": 1,
+		"inspecting":  1,
+		"none":        1,
+	}, profile.Status.MatchedHostsByState)
+	assert.Equal(t, 2, profile.Status.ConsumedCount)
+	assert.Equal(t, 2, profile.Status.AvailableCount)
+
+	setMatchedHostStates(&profile, hosts[6:], labelKey)
+	assert.Nil(t, profile.Status.MatchedHostsByState)
+	assert.Equal(t, 0, profile.Status.ConsumedCount)
+	assert.Equal(t, 0, profile.Status.AvailableCount)
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("HardwareClassification status", func() {
+	var namespace string
+
+	BeforeEach(func() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "status-"}}
+		Expect(k8sClient.Create(context.TODO(), ns)).To(Succeed())
+		namespace = ns.Name
+	})
+
+	createHost := func(name string, labels map[string]string, status bmh.BareMetalHostStatus) {
+		host := &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    labels,
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), host)).To(Succeed())
+		host.Status = status
+		Expect(k8sClient.Status().Update(context.TODO(), host)).To(Succeed())
+	}
+
+	It("is complete after a single reconcile", func() {
+		profile := &hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "profile",
+				Namespace:  namespace,
+				Finalizers: []string{hwcc.Finalizer},
+			},
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 8},
+				},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), profile)).To(Succeed())
+		labelKey, labelValue := getLabelDetails(profile)
+
+		createHost("matched", map[string]string{labelKey: labelValue}, bmh.BareMetalHostStatus{
+			OperationalStatus: bmh.OperationalStatusOK,
+			HardwareDetails: &bmh.HardwareDetails{
+				CPU:     bmh.CPU{Count: 16, Flags: []string{}},
+				NIC:     []bmh.NIC{},
+				Storage: []bmh.Storage{},
+			},
+		})
+		createHost("unmatched", nil, bmh.BareMetalHostStatus{
+			OperationalStatus: bmh.OperationalStatusOK,
+			HardwareDetails: &bmh.HardwareDetails{
+				CPU:     bmh.CPU{Count: 4, Flags: []string{}},
+				NIC:     []bmh.NIC{},
+				Storage: []bmh.Storage{},
+			},
+		})
+		createHost("failed", nil, bmh.BareMetalHostStatus{
+			OperationalStatus: bmh.OperationalStatusError,
+			ErrorType:         bmh.InspectionError,
+		})
+
+		r := &HardwareClassificationReconciler{
+			Client: k8sClient,
+			Log:    ctrl.Log.WithName("test"),
+			Scheme: scheme.Scheme,
+		}
+		key := types.NamespacedName{Name: profile.Name, Namespace: namespace}
+		_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		stored := &hwcc.HardwareClassification{}
+		Expect(k8sClient.Get(context.TODO(), key, stored)).To(Succeed())
+		Expect(stored.Status.ProfileMatchStatus).To(Equal(hwcc.ProfileMatchStatusMatched))
+		Expect(stored.Status.MatchedCount).To(Equal(hwcc.MatchedCount(1)))
+		Expect(stored.Status.UnmatchedCount).To(Equal(hwcc.UnmatchedCount(1)))
+		Expect(stored.Status.ErrorHosts).To(Equal(hwcc.ErrorHosts(1)))
+		Expect(stored.Status.IntrospectionErrorHosts).To(Equal(hwcc.IntrospectionErrorHosts(1)))
+
+		// Nothing changed, so the status is not written again.
+		_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		unchanged := &hwcc.HardwareClassification{}
+		Expect(k8sClient.Get(context.TODO(), key, unchanged)).To(Succeed())
+		Expect(unchanged.ResourceVersion).To(Equal(stored.ResourceVersion))
+	})
+})
@@ -0,0 +1,191 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+)
+
+// HardwareInventoryReconciler maintains the HardwareInventory of every
+// namespace with BareMetalHosts
+type HardwareInventoryReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// Reconcile groups the hosts of the namespace by hardware and records
+// the groups, and how the profiles applying to the namespace cover the
+// hosts, in the status of the inventory of the namespace, creating it
+// when the namespace has hosts.
+func (r *HardwareInventoryReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	logger := r.Log.WithValues("namespace", req.Namespace)
+
+	// Inventories created under other names are left alone.
+	if req.Name != hwcc.HardwareInventoryName {
+		return ctrl.Result{}, nil
+	}
+
+	hosts := bmh.BareMetalHostList{}
+	if err := r.List(ctx, &hosts, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "could not fetch host list")
+	}
+
+	inventory := &hwcc.HardwareInventory{}
+	if err := r.Get(ctx, req.NamespacedName, inventory); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if len(hosts.Items) == 0 {
+			unclassifiedHosts.DeleteLabelValues(req.Namespace)
+			return ctrl.Result{}, nil
+		}
+		inventory = &hwcc.HardwareInventory{}
+		inventory.Name = req.Name
+		inventory.Namespace = req.Namespace
+		logger.Info("creating inventory")
+		if err := r.Create(ctx, inventory); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to create inventory")
+		}
+	}
+
+	profiles, err := NamespaceProfiles(ctx, r, req.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	status := inventoryStatus(hosts.Items)
+	status.Coverage, err = classifier.DefaultEngine.Coverage(ctx, profiles, hosts.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	unclassifiedHosts.WithLabelValues(req.Namespace).Set(float64(status.Coverage.UnclassifiedCount))
+	if equality.Semantic.DeepEqual(inventory.Status, status) {
+		return ctrl.Result{}, nil
+	}
+	original := inventory.DeepCopy()
+	inventory.Status = status
+	logger.V(1).Info("updating inventory", "hosts", status.TotalHosts, "groups", status.GroupCount)
+	if err := r.Status().Patch(ctx, inventory, client.MergeFrom(original)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to update status")
+	}
+	return ctrl.Result{}, nil
+}
+
+// inventoryStatus groups the hosts by hardware.
+func inventoryStatus(hosts []bmh.BareMetalHost) hwcc.HardwareInventoryStatus {
+	groups, uninspected := classifier.GroupHosts(hosts)
+	return hwcc.HardwareInventoryStatus{
+		TotalHosts:       len(hosts),
+		UninspectedHosts: uninspected,
+		GroupCount:       len(groups),
+		Groups:           groups,
+	}
+}
+
+// inventoryMapper maps a host or a profile to the inventory of its
+// namespace.
+func inventoryMapper(obj handler.MapObject) []ctrl.Request {
+	return []ctrl.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      hwcc.HardwareInventoryName,
+			Namespace: obj.Meta.GetNamespace(),
+		},
+	}}
+}
+
+// namespaceInventoryMapper maps a namespace to its inventory, as its
+// labels select the cluster profiles applying to it.
+func namespaceInventoryMapper(obj handler.MapObject) []ctrl.Request {
+	return []ctrl.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      hwcc.HardwareInventoryName,
+			Namespace: obj.Meta.GetName(),
+		},
+	}}
+}
+
+// allInventoriesMapper maps cluster profiles and templates, which may
+// apply to any namespace, to every inventory.
+type allInventoriesMapper struct {
+	client client.Client
+}
+
+func (m *allInventoriesMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("HardwareInventory").WithName("mapper")
+
+	inventoryList := hwcc.HardwareInventoryList{}
+	if err := m.client.List(context.TODO(), &inventoryList); err != nil {
+		log.Error(err, "could not fetch hardware inventory list")
+		return nil
+	}
+	requests := []ctrl.Request{}
+	for _, inventory := range inventoryList.Items {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      inventory.Name,
+				Namespace: inventory.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager will add watches for this controller
+func (r *HardwareInventoryReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	allMapper := allInventoriesMapper{
+		client: mgr.GetClient(),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hwcc.HardwareInventory{}).
+		Named("hardware-inventory").
+		Watches(&source.Kind{Type: &bmh.BareMetalHost{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(inventoryMapper)},
+			builder.WithPredicates(hostInventoryChanged)).
+		Watches(&source.Kind{Type: &hwcc.HardwareClassification{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(inventoryMapper)},
+			builder.WithPredicates(profileSpecChanged)).
+		Watches(&source.Kind{Type: &hwcc.ClusterHardwareClassification{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &allMapper},
+			builder.WithPredicates(profileSpecChanged)).
+		Watches(&source.Kind{Type: &hwcc.HardwareClassificationTemplate{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: &allMapper},
+			builder.WithPredicates(profileSpecChanged)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(namespaceInventoryMapper)},
+			builder.WithPredicates(namespaceLabelsChanged)).
+		WithOptions(options).
+		Complete(r)
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestHardwareInventoryReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	host := func(name string, cpuCount int) *bmh.BareMetalHost {
+		host := &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metal3"},
+		}
+		if cpuCount > 0 {
+			host.Status.HardwareDetails = &bmh.HardwareDetails{
+				CPU: bmh.CPU{Arch: "x86_64", Count: cpuCount},
+			}
+		}
+		return host
+	}
+
+	profile := func(name string, cpu hwcc.Cpu) *hwcc.HardwareClassification {
+		return &hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metal3"},
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{Cpu: &cpu},
+			},
+		}
+	}
+
+	testCases := []struct {
+		Scenario  string
+		Namespace string
+		Name      string
+		Status    *hwcc.HardwareInventoryStatus
+	}{
+		{
+			Scenario:  "hosts",
+			Namespace: "metal3",
+			Name:      hwcc.HardwareInventoryName,
+			Status: &hwcc.HardwareInventoryStatus{
+				TotalHosts:       4,
+				UninspectedHosts: 1,
+				GroupCount:       2,
+				Groups: []hwcc.HardwareGroup{
+					{
+						Hardware: hwcc.HardwareSignature{CPU: hwcc.CPUSignature{Arch: "x86_64", Count: 16}},
+						Count:    2,
+						Hosts:    []string{"host-0", "host-2"},
+					},
+					{
+						Hardware: hwcc.HardwareSignature{CPU: hwcc.CPUSignature{Arch: "x86_64", Count: 32}},
+						Count:    1,
+						Hosts:    []string{"host-1"},
+					},
+				},
+				Coverage: &hwcc.ProfileCoverage{
+					ProfileCount:     2,
+					OverlappingCount: 2,
+					OverlappingHosts: []hwcc.HostOverlap{
+						{Host: "host-0", Profiles: []string{"small", "x86"}},
+						{Host: "host-2", Profiles: []string{"small", "x86"}},
+					},
+					SubsumedProfiles: []hwcc.ProfileSubsumption{
+						{Profile: "small", SubsumedBy: "x86", Basis: hwcc.SubsumptionBasisHosts},
+					},
+				},
+			},
+		},
+		{
+			Scenario:  "no-hosts",
+			Namespace: "empty",
+			Name:      hwcc.HardwareInventoryName,
+		},
+		{
+			Scenario:  "other-name",
+			Namespace: "metal3",
+			Name:      "inventory",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(scheme,
+				host("host-0", 16), host("host-1", 32), host("host-2", 16), host("host-3", 0),
+				profile("small", hwcc.Cpu{MaximumCount: 16}), profile("x86", hwcc.Cpu{Architecture: "x86_64"}))
+			r := &HardwareInventoryReconciler{
+				Client: c,
+				Log:    ctrl.Log.WithName("test"),
+				Scheme: scheme,
+			}
+			key := types.NamespacedName{Namespace: tc.Namespace, Name: tc.Name}
+
+			_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			assert.NoError(t, err)
+
+			inventory := &hwcc.HardwareInventory{}
+			err = c.Get(context.TODO(), key, inventory)
+			if tc.Status == nil {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, *tc.Status, inventory.Status)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// templateRefField indexes profiles and cluster profiles by the
+// template they reference, as "<namespace>/<name>".
+const templateRefField = ".spec.templateRef"
+
+// profileLabelField indexes hosts by the keys of the labels of the
+// profiles and cluster profiles they are labelled for.
+const profileLabelField = ".metadata.labels.profiles"
+
+// SetupIndexes registers the cache field indexes used by the mappers
+// of the controllers. It has to be called once, before the controllers
+// are set up.
+func SetupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	err := indexer.IndexField(ctx, &hwcc.HardwareClassification{}, templateRefField, profileTemplateRef)
+	if err != nil {
+		return errors.Wrap(err, "could not index hardware classifications by template")
+	}
+	err = indexer.IndexField(ctx, &hwcc.ClusterHardwareClassification{}, templateRefField, clusterProfileTemplateRef)
+	if err != nil {
+		return errors.Wrap(err, "could not index cluster hardware classifications by template")
+	}
+	err = indexer.IndexField(ctx, &bmh.BareMetalHost{}, profileLabelField, hostProfileLabels)
+	if err != nil {
+		return errors.Wrap(err, "could not index hosts by profile label")
+	}
+	return nil
+}
+
+// templateRefKey returns the index key of a template reference, the
+// namespace defaults to the one of the referencing profile.
+func templateRefKey(ref *hwcc.TemplateReference, namespace string) string {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return namespace + "/" + ref.Name
+}
+
+func profileTemplateRef(obj runtime.Object) []string {
+	profile, ok := obj.(*hwcc.HardwareClassification)
+	if !ok || profile.Spec.TemplateRef == nil {
+		return nil
+	}
+	return []string{templateRefKey(profile.Spec.TemplateRef, profile.Namespace)}
+}
+
+func clusterProfileTemplateRef(obj runtime.Object) []string {
+	clusterProfile, ok := obj.(*hwcc.ClusterHardwareClassification)
+	if !ok || clusterProfile.Spec.TemplateRef == nil {
+		return nil
+	}
+	return []string{templateRefKey(clusterProfile.Spec.TemplateRef, "")}
+}
+
+func hostProfileLabels(obj runtime.Object) []string {
+	host, ok := obj.(*bmh.BareMetalHost)
+	if !ok {
+		return nil
+	}
+	keys := []string{}
+	for key := range host.GetLabels() {
+		if strings.HasPrefix(key, defaultLabelName) || strings.HasPrefix(key, clusterLabelName) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// labelledHosts lists the hosts of the namespace, or of all namespaces
+// when it is empty, labelled with the label of a profile or cluster
+// profile.
+func labelledHosts(ctx context.Context, c client.Reader, namespace, labelKey string) ([]bmh.BareMetalHost, error) {
+	hostList := bmh.BareMetalHostList{}
+	err := c.List(ctx, &hostList,
+		client.InNamespace(namespace), client.MatchingFields{profileLabelField: labelKey})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch labelled host list")
+	}
+	hosts := []bmh.BareMetalHost{}
+	for i := range hostList.Items {
+		if hasLabel(&hostList.Items[i], labelKey) {
+			hosts = append(hosts, hostList.Items[i])
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestTemplateRefIndex(t *testing.T) {
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "metal3"},
+	}
+	assert.Nil(t, profileTemplateRef(profile))
+
+	profile.Spec.TemplateRef = &hwcc.TemplateReference{Name: "template"}
+	assert.Equal(t, []string{"metal3/template"}, profileTemplateRef(profile))
+
+	profile.Spec.TemplateRef.Namespace = "shared"
+	assert.Equal(t, []string{"shared/template"}, profileTemplateRef(profile))
+
+	clusterProfile := &hwcc.ClusterHardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-profile"},
+	}
+	clusterProfile.Spec.TemplateRef = &hwcc.TemplateReference{Name: "template", Namespace: "shared"}
+	assert.Equal(t, []string{"shared/template"}, clusterProfileTemplateRef(clusterProfile))
+	assert.Nil(t, clusterProfileTemplateRef(profile))
+}
+
+func TestHostProfileLabelsIndex(t *testing.T) {
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{Name: "host", Namespace: "metal3"},
+	}
+	assert.Empty(t, hostProfileLabels(host))
+
+	host.Labels = map[string]string{
+		defaultLabelName + "profile": defaultLabelValue,
+		clusterLabelName + "profile": defaultLabelValue,
+		DefaultFailureLabel:          "inspection-error",
+		"other":                      "value",
+	}
+	assert.ElementsMatch(t, []string{defaultLabelName + "profile", clusterLabelName + "profile"},
+		hostProfileLabels(host))
+	assert.Nil(t, hostProfileLabels(&hwcc.HardwareClassification{}))
+}
+
+func TestLabelledClassificationMapper(t *testing.T) {
+	host := newHostWithCPUs("host", 4)
+	host.Labels = map[string]string{
+		"hardwareclassification.metal3.io/compute": "matches",
+		"hardwareclassification.metal3.io/storage": "matches",
+		hardwareDriftLabel:                         "true",
+	}
+
+	mapper := labelledClassificationMapper{}
+	assert.Equal(t, []ctrl.Request{
+		{NamespacedName: types.NamespacedName{Name: "compute", Namespace: "namespace"}},
+		{NamespacedName: types.NamespacedName{Name: "storage", Namespace: "namespace"}},
+	}, mapper.Map(handler.MapObject{Meta: &host, Object: &host}))
+}
+
+func TestTemplateHostsMapper(t *testing.T) {
+	template := &hwcc.HardwareClassificationTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "shared"},
+	}
+	hosts := []runtime.Object{}
+	for _, namespace := range []string{"metal3", "other", "shared"} {
+		hosts = append(hosts, &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: "host", Namespace: namespace},
+		})
+	}
+	profile := func(namespace string, ref *hwcc.TemplateReference) runtime.Object {
+		return &hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: namespace},
+			Spec:       hwcc.HardwareClassificationSpec{TemplateRef: ref},
+		}
+	}
+
+	testCases := []struct {
+		Scenario string
+		Profiles []runtime.Object
+		Expected []string
+	}{
+		{
+			Scenario: "no reference",
+			Profiles: []runtime.Object{
+				profile("metal3", nil),
+				profile("other", &hwcc.TemplateReference{Name: "template"}),
+			},
+			Expected: []string{},
+		},
+		{
+			Scenario: "profiles",
+			Profiles: []runtime.Object{
+				profile("metal3", &hwcc.TemplateReference{Name: "template", Namespace: "shared"}),
+				profile("shared", &hwcc.TemplateReference{Name: "template"}),
+			},
+			Expected: []string{"metal3", "shared"},
+		},
+		{
+			Scenario: "cluster profile",
+			Profiles: []runtime.Object{
+				&hwcc.ClusterHardwareClassification{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster-profile"},
+					Spec: hwcc.ClusterHardwareClassificationSpec{
+						HardwareClassificationSpec: hwcc.HardwareClassificationSpec{
+							TemplateRef: &hwcc.TemplateReference{Name: "template", Namespace: "shared"},
+						},
+					},
+				},
+			},
+			Expected: []string{"metal3", "other", "shared"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NoError(t, bmh.AddToScheme(scheme))
+			assert.NoError(t, hwcc.AddToScheme(scheme))
+			mapper := templateHostsMapper{
+				client: fake.NewFakeClientWithScheme(scheme, append(tc.Profiles, hosts...)...),
+			}
+
+			namespaces := []string{}
+			for _, request := range mapper.Map(handler.MapObject{Meta: template, Object: template}) {
+				namespaces = append(namespaces, request.Namespace)
+			}
+			assert.ElementsMatch(t, tc.Expected, namespaces)
+		})
+	}
+}
+
+func TestLabelledHostsMapper(t *testing.T) {
+	host := func(namespace, name string, labels map[string]string) runtime.Object {
+		return &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		}
+	}
+	hosts := []runtime.Object{
+		host("metal3", "labelled", map[string]string{
+			defaultLabelName + "profile": defaultLabelValue,
+			clusterLabelName + "profile": defaultLabelValue,
+		}),
+		host("metal3", "other", map[string]string{defaultLabelName + "other": defaultLabelValue}),
+		host("metal3", "selected", nil),
+		host("shared", "labelled", map[string]string{clusterLabelName + "profile": defaultLabelValue}),
+		host("shared", "selected", nil),
+		host("shared", "unlabelled", nil),
+	}
+
+	testCases := []struct {
+		Scenario string
+		Profile  runtime.Object
+		Expected []string
+	}{
+		{
+			Scenario: "profile",
+			Profile: &hwcc.HardwareClassification{
+				ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "metal3"},
+				Status:     hwcc.HardwareClassificationStatus{SelectedHosts: []string{"selected"}},
+			},
+			Expected: []string{"metal3/labelled", "metal3/selected"},
+		},
+		{
+			Scenario: "cluster profile",
+			Profile: &hwcc.ClusterHardwareClassification{
+				ObjectMeta: metav1.ObjectMeta{Name: "profile"},
+				Status: hwcc.HardwareClassificationStatus{
+					SelectedHosts: []string{"shared/selected", "invalid"},
+				},
+			},
+			Expected: []string{"metal3/labelled", "shared/labelled", "shared/selected"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NoError(t, bmh.AddToScheme(scheme))
+			assert.NoError(t, hwcc.AddToScheme(scheme))
+			mapper := labelledHostsMapper{
+				client: fake.NewFakeClientWithScheme(scheme, hosts...),
+			}
+
+			meta, _ := tc.Profile.(metav1.Object)
+			names := []string{}
+			for _, request := range mapper.Map(handler.MapObject{Meta: meta, Object: tc.Profile}) {
+				names = append(names, request.String())
+			}
+			assert.ElementsMatch(t, tc.Expected, names)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+	"github.com/metal3-io/hardware-classification-controller/utils"
+)
+
+const (
+	// inspectAnnotation is set by users on a host to request its
+	// inspection, or to disable it with the inspectDisabled value. The
+	// baremetal-operator removes it once the inspection started.
+	inspectAnnotation = "inspect.metal3.io"
+	inspectDisabled   = "disabled"
+
+	inspectionAnnotationPrefix = "inspection.hardwareclassification.metal3.io/"
+
+	// inspectionTimestampAnnotation records the end of the inspection
+	// whose hardware details the host was classified with.
+	inspectionTimestampAnnotation = inspectionAnnotationPrefix + "timestamp"
+	// clearedProfilesAnnotation records the profiles whose labels were
+	// removed when the inspection of the host restarted, so a drift
+	// detected once it completes is reported to them.
+	clearedProfilesAnnotation = inspectionAnnotationPrefix + "cleared-profiles"
+)
+
+// inspectionInProgress reports whether the host is being inspected, or
+// its inspection was requested, so its hardware details are about to
+// be replaced. The baremetal-operator only acts on inspection requests
+// for hosts which are ready or available, not in use and not in error,
+// so requests on other hosts leave their labels alone.
+func inspectionInProgress(host *bmh.BareMetalHost) bool {
+	if host.Status.Provisioning.State == bmh.StateInspecting {
+		return true
+	}
+	value, requested := host.GetAnnotations()[inspectAnnotation]
+	if !requested || value == inspectDisabled {
+		return false
+	}
+	switch host.Status.Provisioning.State {
+	case bmh.StateReady, bmh.StateAvailable:
+		return !hostInUse(host) && !hostFailed(host)
+	default:
+		return false
+	}
+}
+
+// inspectionCompleted reports whether an inspection of the host ended
+// after the last one started.
+func inspectionCompleted(host *bmh.BareMetalHost) bool {
+	inspect := host.Status.OperationHistory.Inspect
+	return !inspect.End.IsZero() && !inspect.End.Before(&inspect.Start)
+}
+
+// setInspectionTimestamp records the end of the inspection the host is
+// classified with. Hardware details not coming from an inspection
+// have no timestamp.
+func setInspectionTimestamp(host *bmh.BareMetalHost) bool {
+	end := host.Status.OperationHistory.Inspect.End
+	if end.IsZero() {
+		return deleteAnnotation(host, inspectionTimestampAnnotation)
+	}
+	return setAnnotation(host, inspectionTimestampAnnotation, end.UTC().Format(time.RFC3339))
+}
+
+// clearedProfiles returns the names of the profiles whose labels were
+// removed when the inspection of the host restarted.
+func clearedProfiles(host *bmh.BareMetalHost) []string {
+	value := host.GetAnnotations()[clearedProfilesAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// clearClassification removes the labels and score annotations set for
+// the hardware details of a host being inspected again, and its fact
+// and unclassified labels, and reports whether the host changed. The
+// labels of profiles whose unlabel policy keeps them, or whose template
+// cannot be resolved, stay.
+func (r *BareMetalHostReconciler) clearClassification(logger logr.Logger, host *bmh.BareMetalHost) (bool, error) {
+	kept, err := r.keptLabels(host)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	cleared := clearedProfiles(host)
+	for key := range host.GetLabels() {
+		switch {
+		case kept[key]:
+			continue
+		case strings.HasPrefix(key, defaultLabelName) && key != unclassifiedLabel:
+			name := strings.TrimPrefix(key, defaultLabelName)
+			if !utils.StringInList(cleared, name) {
+				cleared = append(cleared, name)
+			}
+		case strings.HasPrefix(key, clusterLabelName):
+			name := clusterProfilePrefix + strings.TrimPrefix(key, clusterLabelName)
+			if !utils.StringInList(cleared, name) {
+				cleared = append(cleared, name)
+			}
+		case key == unclassifiedLabel,
+			strings.HasPrefix(key, classifier.FactLabelPrefix):
+		default:
+			continue
+		}
+		deleteLabel(host, key)
+		logger.Info("inspection in progress, removed label", "name", key)
+		changed = true
+	}
+
+	for key := range host.GetAnnotations() {
+		var labelKey string
+		switch {
+		case strings.HasPrefix(key, scoreAnnotationName):
+			labelKey = defaultLabelName + strings.TrimPrefix(key, scoreAnnotationName)
+		case strings.HasPrefix(key, clusterScoreAnnotationName):
+			labelKey = clusterLabelName + strings.TrimPrefix(key, clusterScoreAnnotationName)
+		default:
+			continue
+		}
+		if !kept[labelKey] {
+			changed = deleteAnnotation(host, key) || changed
+		}
+	}
+
+	if len(cleared) > 0 {
+		sort.Strings(cleared)
+		changed = setAnnotation(host, clearedProfilesAnnotation, strings.Join(cleared, ",")) || changed
+	}
+	return changed, nil
+}
+
+// keptLabels returns the keys of the profile and cluster profile labels
+// of the host to keep while it is inspected.
+func (r *BareMetalHostReconciler) keptLabels(host *bmh.BareMetalHost) (map[string]bool, error) {
+	ctx := context.TODO()
+	kept := map[string]bool{}
+	keep := func(profile *hwcc.HardwareClassification, labelKey string) {
+		if !hasLabel(host, labelKey) || !profile.DeletionTimestamp.IsZero() {
+			return
+		}
+		if err := resolveTemplate(ctx, r, profile); err != nil || keepStaleLabel(profile, host) {
+			kept[labelKey] = true
+		}
+	}
+
+	profileList := hwcc.HardwareClassificationList{}
+	err := r.List(ctx, &profileList, client.InNamespace(host.Namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch classification profiles")
+	}
+	for i := range profileList.Items {
+		profile := &profileList.Items[i]
+		if reservedProfileName(profile) {
+			continue
+		}
+		labelKey, _ := getLabelDetails(profile)
+		keep(profile, labelKey)
+	}
+
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := r.List(ctx, &clusterProfileList); err != nil {
+		return nil, errors.Wrap(err, "could not fetch cluster classification profiles")
+	}
+	for i := range clusterProfileList.Items {
+		clusterProfile := &clusterProfileList.Items[i]
+		labelKey, _ := getClusterLabelDetails(clusterProfile)
+		keep(profileFromCluster(clusterProfile), labelKey)
+	}
+	return kept, nil
+}
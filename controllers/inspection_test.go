@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+)
+
+func TestInspectionInProgress(t *testing.T) {
+	testCases := []struct {
+		Scenario    string
+		State       bmh.ProvisioningState
+		Status      bmh.OperationalStatus
+		Annotations map[string]string
+		Expected    bool
+	}{
+		{
+			Scenario: "inspecting",
+			State:    bmh.StateInspecting,
+			Expected: true,
+		},
+		{
+			Scenario: "ready",
+			State:    bmh.StateReady,
+		},
+		{
+			Scenario:    "inspection requested",
+			State:       bmh.StateReady,
+			Annotations: map[string]string{inspectAnnotation: ""},
+			Expected:    true,
+		},
+		{
+			Scenario:    "inspection disabled",
+			State:       bmh.StateReady,
+			Annotations: map[string]string{inspectAnnotation: inspectDisabled},
+		},
+		{
+			Scenario:    "inspection requested on provisioned host",
+			State:       bmh.StateProvisioned,
+			Annotations: map[string]string{inspectAnnotation: ""},
+		},
+		{
+			Scenario:    "inspection requested on available host",
+			State:       bmh.StateAvailable,
+			Annotations: map[string]string{inspectAnnotation: ""},
+			Expected:    true,
+		},
+		{
+			Scenario:    "inspection requested on registering host",
+			State:       bmh.StateRegistering,
+			Annotations: map[string]string{inspectAnnotation: ""},
+		},
+		{
+			Scenario:    "inspection requested on host stuck in registration",
+			State:       bmh.StateRegistrationError,
+			Status:      bmh.OperationalStatusError,
+			Annotations: map[string]string{inspectAnnotation: ""},
+		},
+		{
+			Scenario:    "inspection requested on host with power management error",
+			State:       bmh.StatePowerManagementError,
+			Status:      bmh.OperationalStatusError,
+			Annotations: map[string]string{inspectAnnotation: ""},
+		},
+		{
+			Scenario:    "inspection requested on ready host in error",
+			State:       bmh.StateReady,
+			Status:      bmh.OperationalStatusError,
+			Annotations: map[string]string{inspectAnnotation: ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			host := &bmh.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.Annotations},
+				Status: bmh.BareMetalHostStatus{
+					Provisioning:      bmh.ProvisionStatus{State: tc.State},
+					OperationalStatus: tc.Status,
+				},
+			}
+			assert.Equal(t, tc.Expected, inspectionInProgress(host))
+		})
+	}
+}
+
+func TestInspectionCompleted(t *testing.T) {
+	start := metav1.NewTime(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(5 * time.Minute))
+
+	testCases := []struct {
+		Scenario string
+		Inspect  bmh.OperationMetric
+		Expected bool
+	}{
+		{
+			Scenario: "never inspected",
+		},
+		{
+			Scenario: "inspecting",
+			Inspect:  bmh.OperationMetric{Start: start},
+		},
+		{
+			Scenario: "inspected",
+			Inspect:  bmh.OperationMetric{Start: start, End: end},
+			Expected: true,
+		},
+		{
+			Scenario: "inspecting again",
+			Inspect:  bmh.OperationMetric{Start: metav1.NewTime(end.Add(time.Hour)), End: end},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			host := &bmh.BareMetalHost{}
+			host.Status.OperationHistory.Inspect = tc.Inspect
+			assert.Equal(t, tc.Expected, inspectionCompleted(host))
+		})
+	}
+}
+
+func TestClearClassification(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "host-0",
+			Namespace: "metal3",
+			Labels: map[string]string{
+				defaultLabelName + "large":               defaultLabelValue,
+				defaultLabelName + "pinned":              defaultLabelValue,
+				unclassifiedLabel:                        "true",
+				clusterLabelName + "fleet":               defaultLabelValue,
+				classifier.FactLabelPrefix + "cpu-count": "32",
+				"example.com/rack":                       "a1",
+			},
+			Annotations: map[string]string{
+				scoreAnnotationName + "large":     "100",
+				scoreAnnotationName + "pinned":    "100",
+				clearedProfilesAnnotation:         "small",
+				inspectionTimestampAnnotation:     "2020-10-01T10:05:00Z",
+				clusterScoreAnnotationName + "xl": "20",
+			},
+		},
+		Status: bmh.BareMetalHostStatus{
+			Provisioning: bmh.ProvisionStatus{State: bmh.StateInspecting},
+		},
+	}
+	pinned := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "metal3"},
+		Spec:       hwcc.HardwareClassificationSpec{UnlabelPolicy: hwcc.UnlabelPolicyNever},
+	}
+	r := &BareMetalHostReconciler{
+		Client: fake.NewFakeClientWithScheme(scheme, pinned),
+		Log:    ctrl.Log.WithName("test"),
+		Scheme: scheme,
+	}
+
+	changed, err := r.clearClassification(r.Log, host)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]string{
+		defaultLabelName + "pinned": defaultLabelValue,
+		"example.com/rack":          "a1",
+	}, host.Labels)
+	assert.Equal(t, map[string]string{
+		scoreAnnotationName + "pinned": "100",
+		clearedProfilesAnnotation:      clusterProfilePrefix + "fleet,large,small",
+		inspectionTimestampAnnotation:  "2020-10-01T10:05:00Z",
+	}, host.Annotations)
+
+	changed, err = r.clearClassification(r.Log, host)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestReconcileInspected(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	// The labels of the host were removed when it was inspected again.
+	start := metav1.NewTime(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "host-0",
+			Namespace:       "metal3",
+			ResourceVersion: "1",
+			Annotations: map[string]string{
+				hardwareHashAnnotation:    classifier.HardwareHashPrefix + "old",
+				clearedProfilesAnnotation: "large,small",
+			},
+		},
+		Status: bmh.BareMetalHostStatus{
+			Provisioning:    bmh.ProvisionStatus{State: bmh.StateReady},
+			HardwareDetails: &bmh.HardwareDetails{CPU: bmh.CPU{Count: 32}},
+			OperationHistory: bmh.OperationHistory{
+				Inspect: bmh.OperationMetric{Start: start, End: metav1.NewTime(start.Add(5 * time.Minute))},
+			},
+		},
+	}
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "metal3"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 16},
+			},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, host, profile)
+	r := &BareMetalHostReconciler{
+		Client:            c,
+		Log:               ctrl.Log.WithName("test"),
+		Scheme:            scheme,
+		Recorder:          record.NewFakeRecorder(10),
+		WaitForInspection: true,
+	}
+	key := types.NamespacedName{Name: "host-0", Namespace: "metal3"}
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), key, host))
+	assert.Contains(t, host.Labels, defaultLabelName+"large")
+	assert.Equal(t, "2020-10-01T10:05:00Z", host.Annotations[inspectionTimestampAnnotation])
+	if drift := hostDrift(host); assert.NotNil(t, drift) {
+		assert.Equal(t, []string{"large", "small"}, drift.Profiles)
+	}
+}
+
+func TestReconcileInspectionRequestedInRegistrationError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	// The baremetal-operator does not inspect hosts stuck in
+	// registration, so the request does not clear their labels.
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "host-0",
+			Namespace:       "metal3",
+			ResourceVersion: "1",
+			Labels:          map[string]string{defaultLabelName + "large": defaultLabelValue},
+			Annotations:     map[string]string{inspectAnnotation: ""},
+		},
+		Status: bmh.BareMetalHostStatus{
+			Provisioning:      bmh.ProvisionStatus{State: bmh.StateRegistrationError},
+			OperationalStatus: bmh.OperationalStatusError,
+			ErrorType:         bmh.RegistrationError,
+			HardwareDetails:   &bmh.HardwareDetails{CPU: bmh.CPU{Count: 32}},
+		},
+	}
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "metal3"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 16},
+			},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, host, profile)
+	r := &BareMetalHostReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	key := types.NamespacedName{Name: "host-0", Namespace: "metal3"}
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), key, host))
+	assert.Equal(t, defaultLabelValue, host.Labels[defaultLabelName+"large"])
+	assert.NotContains(t, host.Annotations, clearedProfilesAnnotation)
+}
+
+func TestReconcileWaitForInspection(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	// Hardware details provided without inspection.
+	host := &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "host-0",
+			Namespace:       "metal3",
+			ResourceVersion: "1",
+			Annotations:     map[string]string{inspectAnnotation: inspectDisabled},
+		},
+		Status: bmh.BareMetalHostStatus{
+			Provisioning:    bmh.ProvisionStatus{State: bmh.StateReady},
+			HardwareDetails: &bmh.HardwareDetails{CPU: bmh.CPU{Count: 32}},
+		},
+	}
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "metal3"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 16},
+			},
+		},
+	}
+
+	for _, wait := range []bool{true, false} {
+		c := fake.NewFakeClientWithScheme(scheme, host.DeepCopy(), profile.DeepCopy())
+		r := &BareMetalHostReconciler{
+			Client:            c,
+			Log:               ctrl.Log.WithName("test"),
+			Scheme:            scheme,
+			Recorder:          record.NewFakeRecorder(10),
+			WaitForInspection: wait,
+		}
+		key := types.NamespacedName{Name: "host-0", Namespace: "metal3"}
+		_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+		assert.NoError(t, err)
+
+		result := &bmh.BareMetalHost{}
+		assert.NoError(t, c.Get(context.TODO(), key, result))
+		assert.Equal(t, !wait, hasLabel(result, defaultLabelName+"large"), "wait=%v", wait)
+		assert.NotContains(t, result.Annotations, inspectionTimestampAnnotation)
+	}
+}
@@ -0,0 +1,30 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var unclassifiedHosts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "hwcc_unclassified_hosts",
+	Help: "Number of inspected hosts matching no profile, by namespace.",
+}, []string{"namespace"})
+
+func init() {
+	metrics.Registry.MustRegister(unclassifiedHosts)
+}
@@ -0,0 +1,54 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManager is the manager recorded for the fields of the hosts
+// written by the controller.
+const FieldManager = "hardware-classification-controller"
+
+// hostUpdate changes the labels and annotations of the host and reports
+// whether anything changed.
+type hostUpdate func(host *bmh.BareMetalHost) (bool, error)
+
+// patchHost applies the changes made by update to the host with a JSON
+// merge patch. Only the labels and annotations changed by the controller
+// are sent, without the resource version, so the writes of other
+// controllers to the host since it was read, e.g. the status updates of
+// the baremetal-operator, neither conflict with nor are overwritten by
+// the patch. Those writes trigger a new reconcile of the host, which
+// updates the labels from its latest content. The patch is sent again
+// when the API server still reports a conflict.
+func patchHost(ctx context.Context, c client.Client, host *bmh.BareMetalHost, update hostUpdate) (bool, error) {
+	base := host.DeepCopy()
+	changed, err := update(host)
+	if err != nil || !changed {
+		return changed, err
+	}
+
+	patch := client.MergeFrom(base)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return c.Patch(ctx, host, patch, client.FieldOwner(FieldManager))
+	})
+	return changed, err
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPatchClient(t *testing.T) (client.Client, *bmh.BareMetalHost) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, bmh.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme)
+	assert.NoError(t, c.Create(context.TODO(), &bmh.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "host",
+			Namespace: "metal3",
+			Labels:    map[string]string{"owner": "bmo"},
+		},
+	}))
+
+	host := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "host", Namespace: "metal3"}, host))
+	return c, host
+}
+
+func TestPatchHost(t *testing.T) {
+	c, host := newPatchClient(t)
+
+	changed, err := patchHost(context.TODO(), c, host, func(host *bmh.BareMetalHost) (bool, error) {
+		return setLabel(host, "hardwareclassification.metal3.io/profile", "matches"), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	stored := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "host", Namespace: "metal3"}, stored))
+	assert.Equal(t, map[string]string{
+		"owner": "bmo",
+		"hardwareclassification.metal3.io/profile": "matches",
+	}, stored.Labels)
+}
+
+func TestPatchHostUnchanged(t *testing.T) {
+	c, host := newPatchClient(t)
+	resourceVersion := host.ResourceVersion
+
+	changed, err := patchHost(context.TODO(), c, host, func(host *bmh.BareMetalHost) (bool, error) {
+		return setLabel(host, "owner", "bmo"), nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, resourceVersion, host.ResourceVersion)
+}
+
+// conflictClient fails the first patches with a conflict and records
+// the patches sent.
+type conflictClient struct {
+	client.Client
+	conflicts int
+	patches   []string
+}
+
+func (c *conflictClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	c.patches = append(c.patches, string(data))
+	if c.conflicts > 0 {
+		c.conflicts--
+		return apierrors.NewConflict(schema.GroupResource{Resource: "baremetalhosts"}, "host",
+			errors.New("the object has been modified"))
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestPatchHostStale(t *testing.T) {
+	c, host := newPatchClient(t)
+
+	// Another writer updates the host after it was read.
+	concurrent := host.DeepCopy()
+	concurrent.Labels["bmo"] = "changed"
+	concurrent.Spec.Online = true
+	assert.NoError(t, c.Update(context.TODO(), concurrent))
+
+	calls := 0
+	changed, err := patchHost(context.TODO(), c, host, func(host *bmh.BareMetalHost) (bool, error) {
+		calls++
+		return setLabel(host, "hardwareclassification.metal3.io/profile", "matches"), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, calls)
+
+	stored := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "host", Namespace: "metal3"}, stored))
+	assert.True(t, stored.Spec.Online)
+	assert.Equal(t, map[string]string{
+		"owner": "bmo",
+		"bmo":   "changed",
+		"hardwareclassification.metal3.io/profile": "matches",
+	}, stored.Labels)
+}
+
+func TestPatchHostConflict(t *testing.T) {
+	fakeClient, host := newPatchClient(t)
+	c := &conflictClient{Client: fakeClient, conflicts: 2}
+
+	calls := 0
+	changed, err := patchHost(context.TODO(), c, host, func(host *bmh.BareMetalHost) (bool, error) {
+		calls++
+		return setLabel(host, "hardwareclassification.metal3.io/profile", "matches"), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, calls)
+
+	// The same patch, only holding the new label, is sent again.
+	patch := `{"metadata":{"labels":{"hardwareclassification.metal3.io/profile":"matches"}}}`
+	assert.Equal(t, []string{patch, patch, patch}, c.patches)
+
+	stored := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "host", Namespace: "metal3"}, stored))
+	assert.Equal(t, "matches", stored.Labels["hardwareclassification.metal3.io/profile"])
+}
+
+func TestPatchHostConflictRetriesExhausted(t *testing.T) {
+	fakeClient, host := newPatchClient(t)
+	c := &conflictClient{Client: fakeClient, conflicts: 100}
+
+	changed, err := patchHost(context.TODO(), c, host, func(host *bmh.BareMetalHost) (bool, error) {
+		return setLabel(host, "hardwareclassification.metal3.io/profile", "matches"), nil
+	})
+	assert.True(t, apierrors.IsConflict(err))
+	assert.True(t, changed)
+}
+
+func TestPatchHostUpdateError(t *testing.T) {
+	c, host := newPatchClient(t)
+
+	updateErr := errors.New("no profiles")
+	changed, err := patchHost(context.TODO(), c, host, func(host *bmh.BareMetalHost) (bool, error) {
+		return false, updateErr
+	})
+	assert.Equal(t, updateErr, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,194 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// hostMatchChanged passes the host updates which may change the
+// status of the profiles the host is labelled for: its labels and
+// annotations, and the provisioning state and consumer reported for
+// matched hosts. Creations and deletions always pass.
+var hostMatchChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldHost, newHost, ok := updatedHosts(e)
+		if !ok {
+			return true
+		}
+		return !reflect.DeepEqual(oldHost.Labels, newHost.Labels) ||
+			!reflect.DeepEqual(oldHost.Annotations, newHost.Annotations) ||
+			oldHost.Status.Provisioning.State != newHost.Status.Provisioning.State ||
+			!reflect.DeepEqual(oldHost.Spec.ConsumerRef, newHost.Spec.ConsumerRef)
+	},
+}
+
+// hostClassificationChanged passes the host updates which may change
+// how the host is labelled: its hardware details, labels, error state,
+// deletion, provisioning state and consumer, its inspection and the
+// annotations requesting one or recording a drift. Status updates made
+// while the host is inspected or provisioned do not pass. Creations
+// and deletions always pass.
+var hostClassificationChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldHost, newHost, ok := updatedHosts(e)
+		if !ok {
+			return true
+		}
+		return !equality.Semantic.DeepEqual(oldHost.Status.HardwareDetails, newHost.Status.HardwareDetails) ||
+			!reflect.DeepEqual(oldHost.Labels, newHost.Labels) ||
+			oldHost.Status.OperationalStatus != newHost.Status.OperationalStatus ||
+			oldHost.Status.ErrorType != newHost.Status.ErrorType ||
+			oldHost.DeletionTimestamp.IsZero() != newHost.DeletionTimestamp.IsZero() ||
+			oldHost.Status.Provisioning.State != newHost.Status.Provisioning.State ||
+			!reflect.DeepEqual(oldHost.Spec.ConsumerRef, newHost.Spec.ConsumerRef) ||
+			!equality.Semantic.DeepEqual(oldHost.Status.OperationHistory.Inspect, newHost.Status.OperationHistory.Inspect) ||
+			annotationChanged(oldHost, newHost, inspectAnnotation) ||
+			annotationChanged(oldHost, newHost, hardwareDriftAnnotation)
+	},
+}
+
+// hostInventoryChanged passes the host updates which may change the
+// status of every profile in the namespace of the host: its hardware
+// details, its error state and the start of its deletion, which makes
+// it no longer eligible. Creations and deletions always pass.
+var hostInventoryChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldHost, newHost, ok := updatedHosts(e)
+		if !ok {
+			return true
+		}
+		return !equality.Semantic.DeepEqual(oldHost.Status.HardwareDetails, newHost.Status.HardwareDetails) ||
+			oldHost.DeletionTimestamp.IsZero() != newHost.DeletionTimestamp.IsZero() ||
+			oldHost.Status.OperationalStatus != newHost.Status.OperationalStatus ||
+			oldHost.Status.ErrorType != newHost.Status.ErrorType ||
+			oldHost.Status.ErrorMessage != newHost.Status.ErrorMessage
+	},
+}
+
+// profileSpecChanged passes the updates of profiles, cluster profiles
+// and templates which may change how hosts are labelled, ignoring the
+// status updates made by the controllers. The label value of a
+// profile is read from its labels, and its deletion removes its
+// labels from the hosts.
+var profileSpecChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld == nil || e.MetaNew == nil {
+			return true
+		}
+		return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+			!reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels()) ||
+			e.MetaOld.GetDeletionTimestamp().IsZero() != e.MetaNew.GetDeletionTimestamp().IsZero()
+	},
+}
+
+// profileRulesChanged passes the creations of profiles and cluster
+// profiles and the updates of their spec, which may change which of
+// the hosts they apply to match them.
+var profileRulesChanged = predicate.Funcs{
+	DeleteFunc: func(event.DeleteEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld == nil || e.MetaNew == nil {
+			return true
+		}
+		return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration()
+	},
+}
+
+// profileLabelsChanged passes the updates of profiles and cluster
+// profiles changing the value of their label, read from their labels,
+// or starting their deletion, which removes their label from the
+// hosts, and their deletions. Only the hosts labelled for the profile
+// are concerned.
+var profileLabelsChanged = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld == nil || e.MetaNew == nil {
+			return true
+		}
+		return !reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels()) ||
+			e.MetaOld.GetDeletionTimestamp().IsZero() != e.MetaNew.GetDeletionTimestamp().IsZero()
+	},
+}
+
+// profileSelectionChanged passes the status updates of profiles and
+// cluster profiles changing the hosts selected for their label when
+// they set maxHosts, so the hosts entering and leaving the selection
+// are labelled again. Creations and deletions are left to the watches
+// of the profile rules.
+var profileSelectionChanged = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	DeleteFunc: func(event.DeleteEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldSelected, ok := selectedHosts(e.ObjectOld)
+		if !ok {
+			return false
+		}
+		newSelected, ok := selectedHosts(e.ObjectNew)
+		if !ok {
+			return false
+		}
+		return !reflect.DeepEqual(oldSelected, newSelected)
+	},
+}
+
+// namespaceLabelsChanged passes the creations and deletions of
+// namespaces and the updates of their labels, the only changes which
+// may change which namespaces the namespaceSelector of cluster profiles
+// selects.
+var namespaceLabelsChanged = predicate.Funcs{
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld == nil || e.MetaNew == nil {
+			return true
+		}
+		return !reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+	},
+}
+
+func selectedHosts(obj runtime.Object) ([]string, bool) {
+	switch profile := obj.(type) {
+	case *hwcc.HardwareClassification:
+		return profile.Status.SelectedHosts, true
+	case *hwcc.ClusterHardwareClassification:
+		return profile.Status.SelectedHosts, true
+	default:
+		return nil, false
+	}
+}
+
+func annotationChanged(oldHost, newHost *bmh.BareMetalHost, key string) bool {
+	oldValue, oldOK := oldHost.Annotations[key]
+	newValue, newOK := newHost.Annotations[key]
+	return oldOK != newOK || oldValue != newValue
+}
+
+func updatedHosts(e event.UpdateEvent) (oldHost, newHost *bmh.BareMetalHost, ok bool) {
+	oldHost, ok = e.ObjectOld.(*bmh.BareMetalHost)
+	if !ok {
+		return nil, nil, false
+	}
+	newHost, ok = e.ObjectNew.(*bmh.BareMetalHost)
+	return oldHost, newHost, ok
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func hostUpdateEvent(update func(host *bmh.BareMetalHost)) event.UpdateEvent {
+	oldHost := newHostWithCPUs("host", 4)
+	oldHost.Labels = map[string]string{"hardwareclassification.metal3.io/profile": "matches"}
+	oldHost.Status.Provisioning.State = bmh.StateReady
+	oldHost.Status.OperationalStatus = bmh.OperationalStatusOK
+	newHost := oldHost.DeepCopy()
+	update(newHost)
+	return event.UpdateEvent{
+		MetaOld:   &oldHost,
+		ObjectOld: &oldHost,
+		MetaNew:   newHost,
+		ObjectNew: newHost,
+	}
+}
+
+func TestHostPredicates(t *testing.T) {
+	testCases := []struct {
+		Scenario              string
+		Update                func(host *bmh.BareMetalHost)
+		MatchChanged          bool
+		InventoryChanged      bool
+		ClassificationChanged bool
+	}{
+		{
+			Scenario: "power state",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Status.PoweredOn = !host.Status.PoweredOn
+			},
+		},
+		{
+			Scenario: "labels",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Labels = nil
+			},
+			MatchChanged:          true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "annotations",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Annotations = map[string]string{hardwareDriftAnnotation: "{}"}
+			},
+			MatchChanged:          true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "provisioning state",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Status.Provisioning.State = bmh.StateProvisioning
+			},
+			MatchChanged:          true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "consumer",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Spec.ConsumerRef = &corev1.ObjectReference{Name: "machine"}
+			},
+			MatchChanged:          true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "hardware details",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Status.HardwareDetails.CPU.Count = 8
+			},
+			InventoryChanged:      true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "error state",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Status.OperationalStatus = bmh.OperationalStatusError
+				host.Status.ErrorType = bmh.InspectionError
+			},
+			InventoryChanged:      true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "error message",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Status.ErrorMessage = "timeout"
+			},
+			InventoryChanged: true,
+		},
+		{
+			Scenario: "inspection requested",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Annotations = map[string]string{inspectAnnotation: ""}
+			},
+			MatchChanged:          true,
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "other annotation",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Annotations = map[string]string{"baremetalhost.metal3.io/status": "{}"}
+			},
+			MatchChanged: true,
+		},
+		{
+			Scenario: "inspection completed",
+			Update: func(host *bmh.BareMetalHost) {
+				host.Status.OperationHistory.Inspect.End = metav1.Now()
+			},
+			ClassificationChanged: true,
+		},
+		{
+			Scenario: "deletion",
+			Update: func(host *bmh.BareMetalHost) {
+				now := metav1.Now()
+				host.DeletionTimestamp = &now
+			},
+			InventoryChanged:      true,
+			ClassificationChanged: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			e := hostUpdateEvent(tc.Update)
+			assert.Equal(t, tc.MatchChanged, hostMatchChanged.Update(e))
+			assert.Equal(t, tc.InventoryChanged, hostInventoryChanged.Update(e))
+			assert.Equal(t, tc.ClassificationChanged, hostClassificationChanged.Update(e))
+		})
+	}
+}
+
+func TestProfileSpecChanged(t *testing.T) {
+	now := metav1.Now()
+	testCases := []struct {
+		Scenario string
+		Update   func(profile *hwcc.HardwareClassification)
+		Expected bool
+	}{
+		{
+			Scenario: "status",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.Status.MatchedCount = 3
+			},
+		},
+		{
+			Scenario: "generation",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.Generation++
+			},
+			Expected: true,
+		},
+		{
+			Scenario: "label value",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.Labels = map[string]string{"profile": "gpu"}
+			},
+			Expected: true,
+		},
+		{
+			Scenario: "deletion",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.DeletionTimestamp = &now
+			},
+			Expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			oldProfile := &hwcc.HardwareClassification{
+				ObjectMeta: metav1.ObjectMeta{Name: "profile", Generation: 1},
+			}
+			newProfile := oldProfile.DeepCopy()
+			tc.Update(newProfile)
+			assert.Equal(t, tc.Expected, profileSpecChanged.Update(event.UpdateEvent{
+				MetaOld:   oldProfile,
+				ObjectOld: oldProfile,
+				MetaNew:   newProfile,
+				ObjectNew: newProfile,
+			}))
+		})
+	}
+}
+
+func TestProfileHostPredicates(t *testing.T) {
+	now := metav1.Now()
+	testCases := []struct {
+		Scenario      string
+		Update        func(profile *hwcc.HardwareClassification)
+		RulesChanged  bool
+		LabelsChanged bool
+	}{
+		{
+			Scenario: "status",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.Status.MatchedCount = 3
+			},
+		},
+		{
+			Scenario: "generation",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.Generation++
+			},
+			RulesChanged: true,
+		},
+		{
+			Scenario: "label value",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.Labels = map[string]string{"profile": "gpu"}
+			},
+			LabelsChanged: true,
+		},
+		{
+			Scenario: "deletion",
+			Update: func(profile *hwcc.HardwareClassification) {
+				profile.DeletionTimestamp = &now
+			},
+			LabelsChanged: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			oldProfile := &hwcc.HardwareClassification{
+				ObjectMeta: metav1.ObjectMeta{Name: "profile", Generation: 1},
+			}
+			newProfile := oldProfile.DeepCopy()
+			tc.Update(newProfile)
+			e := event.UpdateEvent{
+				MetaOld:   oldProfile,
+				ObjectOld: oldProfile,
+				MetaNew:   newProfile,
+				ObjectNew: newProfile,
+			}
+			assert.Equal(t, tc.RulesChanged, profileRulesChanged.Update(e), "rules")
+			assert.Equal(t, tc.LabelsChanged, profileLabelsChanged.Update(e), "labels")
+		})
+	}
+
+	profile := &hwcc.HardwareClassification{ObjectMeta: metav1.ObjectMeta{Name: "profile"}}
+	assert.True(t, profileRulesChanged.Create(event.CreateEvent{Meta: profile, Object: profile}))
+	assert.False(t, profileLabelsChanged.Create(event.CreateEvent{Meta: profile, Object: profile}))
+	assert.False(t, profileRulesChanged.Delete(event.DeleteEvent{Meta: profile, Object: profile}))
+	assert.True(t, profileLabelsChanged.Delete(event.DeleteEvent{Meta: profile, Object: profile}))
+}
+
+func TestNamespaceLabelsChanged(t *testing.T) {
+	oldNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "metal3", Labels: map[string]string{"env": "prod"}},
+	}
+	update := func(update func(namespace *corev1.Namespace)) event.UpdateEvent {
+		newNamespace := oldNamespace.DeepCopy()
+		update(newNamespace)
+		return event.UpdateEvent{
+			MetaOld:   oldNamespace,
+			ObjectOld: oldNamespace,
+			MetaNew:   newNamespace,
+			ObjectNew: newNamespace,
+		}
+	}
+
+	assert.True(t, namespaceLabelsChanged.Create(event.CreateEvent{Meta: oldNamespace, Object: oldNamespace}))
+	assert.True(t, namespaceLabelsChanged.Delete(event.DeleteEvent{Meta: oldNamespace, Object: oldNamespace}))
+	assert.True(t, namespaceLabelsChanged.Update(update(func(namespace *corev1.Namespace) {
+		namespace.Labels["env"] = "test"
+	})))
+	assert.False(t, namespaceLabelsChanged.Update(update(func(namespace *corev1.Namespace) {
+		namespace.Annotations = map[string]string{"owner": "team"}
+	})))
+	assert.False(t, namespaceLabelsChanged.Update(update(func(namespace *corev1.Namespace) {
+		namespace.Status.Phase = corev1.NamespaceTerminating
+	})))
+}
@@ -0,0 +1,113 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/classifier"
+	"github.com/metal3-io/hardware-classification-controller/utils"
+)
+
+// eligibleHosts returns the hosts matching the profile, ordered by the
+// selection policy of the profile. Hosts which have not been
+// inspected yet or are being deleted are never eligible. Hosts already
+// labelled with labelKey come first among equivalent hosts, so the
+// labels do not move between them.
+func eligibleHosts(profile *hwcc.HardwareClassification, labelKey string, hosts []bmh.BareMetalHost) []*bmh.BareMetalHost {
+	// The context is never done, so classifying cannot fail.
+	results, _ := classifier.DefaultEngine.Classify(context.TODO(),
+		[]hwcc.HardwareClassification{*profile}, hosts)
+
+	eligible := []*bmh.BareMetalHost{}
+	scores := map[*bmh.BareMetalHost]int{}
+	for _, result := range results[0].Matching() {
+		host := result.Host
+		if !host.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if profile.Spec.SelectionPolicy == hwcc.SelectionPolicyBestScore {
+			scores[host] = result.Score
+		}
+		eligible = append(eligible, host)
+	}
+
+	sort.Slice(eligible, func(i, j int) bool {
+		a, b := eligible[i], eligible[j]
+		switch profile.Spec.SelectionPolicy {
+		case hwcc.SelectionPolicyName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case hwcc.SelectionPolicyBestScore:
+			if scores[a] != scores[b] {
+				return scores[a] > scores[b]
+			}
+		default:
+			if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+				return a.CreationTimestamp.Before(&b.CreationTimestamp)
+			}
+		}
+		if labelledA, labelledB := hasLabel(a, labelKey), hasLabel(b, labelKey); labelledA != labelledB {
+			return labelledA
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Namespace < b.Namespace
+	})
+	return eligible
+}
+
+// selectHosts returns the keys of the eligible hosts which should be
+// labelled for the profile, honouring its MaxHosts limit.
+func selectHosts(profile *hwcc.HardwareClassification, eligible []*bmh.BareMetalHost) []string {
+	selected := []string{}
+	for _, host := range eligible {
+		if profile.Spec.MaxHosts > 0 && len(selected) >= profile.Spec.MaxHosts {
+			break
+		}
+		selected = append(selected, selectionKey(profile, host))
+	}
+	return selected
+}
+
+// hostSelected reports whether the host is among the hosts selected
+// for the profile, as recorded in its status by the profile
+// reconciler.
+func hostSelected(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) bool {
+	return utils.StringInList(profile.Status.SelectedHosts, selectionKey(profile, host))
+}
+
+// selectionPending reports whether the profile reconciler has not
+// selected the hosts of the profile yet. A profile limiting the number
+// of hosts it labels always selects some hosts when one matches.
+func selectionPending(profile *hwcc.HardwareClassification) bool {
+	return len(profile.Status.SelectedHosts) == 0
+}
+
+// selectionKey identifies a host in the selection of a profile: its
+// name for profiles, which only apply to their namespace, and
+// "<namespace>/<name>" for cluster profiles.
+func selectionKey(profile *hwcc.HardwareClassification, host *bmh.BareMetalHost) string {
+	if profile.Namespace == "" {
+		return host.Namespace + "/" + host.Name
+	}
+	return host.Name
+}
@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func newSelectionHosts() []bmh.BareMetalHost {
+	now := time.Now()
+	hosts := []bmh.BareMetalHost{
+		newHostWithCPUs("host-c", 32),
+		newHostWithCPUs("host-a", 8),
+		newHostWithCPUs("host-b", 16),
+		newHostWithCPUs("too-small", 2),
+		{ObjectMeta: metav1.ObjectMeta{Name: "not-inspected"}},
+	}
+	for i := range hosts {
+		hosts[i].CreationTimestamp = metav1.NewTime(now.Add(time.Duration(i) * time.Minute))
+	}
+	return hosts
+}
+
+func TestSelectHosts(t *testing.T) {
+	minCPUs := hwcc.HardwareCharacteristics{
+		Cpu: &hwcc.Cpu{MinimumCount: 8},
+	}
+
+	testCases := []struct {
+		Scenario string
+		Spec     hwcc.HardwareClassificationSpec
+		Expected []string
+	}{
+		{
+			Scenario: "no-limit",
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: minCPUs,
+			},
+			Expected: []string{"host-c", "host-a", "host-b"},
+		},
+		{
+			Scenario: "oldest",
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: minCPUs,
+				MaxHosts:                2,
+			},
+			Expected: []string{"host-c", "host-a"},
+		},
+		{
+			Scenario: "name",
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: minCPUs,
+				MaxHosts:                2,
+				SelectionPolicy:         hwcc.SelectionPolicyName,
+			},
+			Expected: []string{"host-a", "host-b"},
+		},
+		{
+			Scenario: "best-score",
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 16},
+					Nic: &hwcc.Nic{},
+				},
+				Scoring:         &hwcc.Scoring{MinimumScore: 50},
+				MaxHosts:        2,
+				SelectionPolicy: hwcc.SelectionPolicyBestScore,
+			},
+			Expected: []string{"host-b", "host-c"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := hwcc.HardwareClassification{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "namespace"},
+				Spec:       tc.Spec,
+			}
+			hosts := newSelectionHosts()
+			selected := selectHosts(&profile, eligibleHosts(&profile, defaultLabelName+"profile", hosts))
+			assert.Equal(t, tc.Expected, selected)
+		})
+	}
+}
+
+func TestSelectHostsPrefersLabelled(t *testing.T) {
+	created := metav1.NewTime(time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC))
+	hosts := []bmh.BareMetalHost{
+		newHostWithCPUs("host-a", 8),
+		newHostWithCPUs("host-b", 8),
+	}
+	for i := range hosts {
+		hosts[i].CreationTimestamp = created
+	}
+	hosts[1].Labels = map[string]string{defaultLabelName + "profile": defaultLabelValue}
+
+	profile := hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "namespace"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+			},
+			MaxHosts: 1,
+		},
+	}
+	labelKey, _ := getLabelDetails(&profile)
+	profile.Status.SelectedHosts = selectHosts(&profile, eligibleHosts(&profile, labelKey, hosts))
+	assert.Equal(t, []string{"host-b"}, profile.Status.SelectedHosts)
+	assert.True(t, hostSelected(&profile, &hosts[1]))
+	assert.False(t, hostSelected(&profile, &hosts[0]))
+
+	// Cluster profiles select hosts of several namespaces.
+	profile.Namespace = ""
+	profile.Status.SelectedHosts = selectHosts(&profile, eligibleHosts(&profile, labelKey, hosts))
+	assert.Equal(t, []string{"namespace/host-b"}, profile.Status.SelectedHosts)
+	assert.True(t, hostSelected(&profile, &hosts[1]))
+	other := hosts[1].DeepCopy()
+	other.Namespace = "other"
+	assert.False(t, hostSelected(&profile, other))
+}
+
+func TestSelectionDisplacesLabelledHost(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "control-plane", Namespace: "namespace"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+			},
+			MaxHosts: 1,
+		},
+	}
+	labelKey, labelValue := getLabelDetails(profile)
+
+	// The labelled host was the only eligible one until the older
+	// host got inspected.
+	labelled := newHostWithCPUs("labelled", 8)
+	labelled.ResourceVersion = "1"
+	labelled.CreationTimestamp = metav1.NewTime(time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC))
+	labelled.Labels = map[string]string{labelKey: labelValue}
+	older := newHostWithCPUs("older", 8)
+	older.ResourceVersion = "1"
+	older.CreationTimestamp = metav1.NewTime(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	profile.ResourceVersion = "1"
+	profile.Status.SelectedHosts = []string{"labelled"}
+	c := fake.NewFakeClientWithScheme(scheme, profile, &labelled, &older)
+	r := &BareMetalHostReconciler{
+		Client:   c,
+		Log:      ctrl.Log.WithName("test"),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	key := types.NamespacedName{Name: "older", Namespace: "namespace"}
+
+	// The host is not labelled until the profile reconciler selects
+	// it, the host reconciler does not compare the hosts itself.
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), key, &older))
+	assert.False(t, hasLabel(&older, labelKey))
+
+	// The profile reconciler reports the new selection, which enqueues
+	// the host losing the label.
+	hostList := bmh.BareMetalHostList{}
+	assert.NoError(t, c.List(context.TODO(), &hostList))
+	oldProfile := profile.DeepCopy()
+	setCapacity(profile, labelKey, hostList.Items, 1)
+	assert.Equal(t, []string{"older"}, profile.Status.SelectedHosts)
+	assert.NoError(t, c.Status().Update(context.TODO(), profile))
+	assert.True(t, profileSelectionChanged.Update(event.UpdateEvent{
+		MetaOld: oldProfile, ObjectOld: oldProfile,
+		MetaNew: profile, ObjectNew: profile,
+	}))
+	mapper := labelledHostsMapper{client: c}
+	requests := mapper.Map(handler.MapObject{Meta: profile, Object: profile})
+	assert.Contains(t, requests, ctrl.Request{NamespacedName: key})
+	assert.Contains(t, requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: "labelled", Namespace: "namespace"}})
+
+	_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Get(context.TODO(), key, &older))
+	assert.True(t, hasLabel(&older, labelKey))
+
+	// The fake client does not remove labels with merge patches, so
+	// classify the displaced host directly.
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "labelled", Namespace: "namespace"}, &labelled))
+	_, changed, err := r.classifyHost(r.Log, &labelled)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.False(t, hasLabel(&labelled, labelKey))
+}
+
+func TestSetCapacity(t *testing.T) {
+	testCases := []struct {
+		Scenario   string
+		MaxHosts   int
+		MinHosts   int
+		Conditions []metav1.Condition
+		Labelled   int
+		Selected   []string
+		// Available is the expected status of the MinHostsAvailable
+		// condition, empty when the condition must be absent.
+		Available metav1.ConditionStatus
+	}{
+		{
+			Scenario:  "not-enough-hosts",
+			MaxHosts:  1,
+			MinHosts:  4,
+			Labelled:  1,
+			Selected:  []string{"host-c"},
+			Available: metav1.ConditionFalse,
+		},
+		{
+			Scenario:  "enough-hosts",
+			MaxHosts:  1,
+			MinHosts:  3,
+			Labelled:  1,
+			Selected:  []string{"host-c"},
+			Available: metav1.ConditionTrue,
+		},
+		{
+			Scenario: "no-minimum-no-conditions",
+			Labelled: 3,
+		},
+		{
+			Scenario: "no-minimum-clears-condition",
+			Conditions: []metav1.Condition{
+				{Type: hwcc.ConditionMinHostsAvailable, Status: metav1.ConditionFalse},
+			},
+			Labelled: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			profile := hwcc.HardwareClassification{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "namespace"},
+				Spec: hwcc.HardwareClassificationSpec{
+					HardwareCharacteristics: hwcc.HardwareCharacteristics{
+						Cpu: &hwcc.Cpu{MinimumCount: 8},
+					},
+					MaxHosts: tc.MaxHosts,
+					MinHosts: tc.MinHosts,
+				},
+				Status: hwcc.HardwareClassificationStatus{
+					Conditions: tc.Conditions,
+				},
+			}
+
+			setCapacity(&profile, defaultLabelName+"profile", newSelectionHosts(), tc.Labelled)
+			assert.Equal(t, 3, profile.Status.EligibleCount)
+			assert.Equal(t, tc.Labelled, profile.Status.LabelledCount)
+			assert.Equal(t, tc.Selected, profile.Status.SelectedHosts)
+			condition := meta.FindStatusCondition(profile.Status.Conditions, hwcc.ConditionMinHostsAvailable)
+			if tc.Available == "" {
+				assert.Nil(t, condition)
+				return
+			}
+			if assert.NotNil(t, condition) {
+				assert.Equal(t, tc.Available, condition.Status)
+			}
+		})
+	}
+}
+
+func TestApplyProfileSelectionPending(t *testing.T) {
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "namespace"},
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 8},
+			},
+			MaxHosts: 1,
+		},
+	}
+	labelKey, labelValue := getLabelDetails(profile)
+	scoreKey := scoreAnnotationName + profile.Name
+
+	// The labels are left alone until the profile reconciler selects
+	// the hosts.
+	labelled := newHostWithCPUs("labelled", 8)
+	labelled.Labels = map[string]string{labelKey: labelValue}
+	unlabelled := newHostWithCPUs("unlabelled", 8)
+	assert.False(t, applyProfile(ctrl.Log, &labelled, profile, labelKey, labelValue, scoreKey))
+	assert.True(t, hasLabel(&labelled, labelKey))
+	assert.False(t, applyProfile(ctrl.Log, &unlabelled, profile, labelKey, labelValue, scoreKey))
+	assert.False(t, hasLabel(&unlabelled, labelKey))
+
+	profile.Status.SelectedHosts = []string{"unlabelled"}
+	assert.True(t, applyProfile(ctrl.Log, &labelled, profile, labelKey, labelValue, scoreKey))
+	assert.False(t, hasLabel(&labelled, labelKey))
+	assert.True(t, applyProfile(ctrl.Log, &unlabelled, profile, labelKey, labelValue, scoreKey))
+	assert.True(t, hasLabel(&unlabelled, labelKey))
+}
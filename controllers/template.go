@@ -0,0 +1,272 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+// errTemplateNamespaceNotAllowed is returned for namespaced profiles
+// referencing a template of another namespace. Only cluster profiles
+// may, so a profile cannot read the templates of other tenants.
+var errTemplateNamespaceNotAllowed = errors.New("templates of other namespaces can only be referenced by cluster profiles")
+
+// resolveTemplate replaces the characteristics of the profile with
+// the characteristics of its template, overridden by the ones set in
+// the profile. Profiles without a template are left untouched.
+func resolveTemplate(ctx context.Context, c client.Reader, profile *hwcc.HardwareClassification) error {
+	ref := profile.Spec.TemplateRef
+	if ref == nil {
+		return nil
+	}
+	if profile.Namespace != "" && ref.Namespace != "" && ref.Namespace != profile.Namespace {
+		return errors.Wrap(errTemplateNamespaceNotAllowed,
+			fmt.Sprintf("could not load template %s/%s", ref.Namespace, ref.Name))
+	}
+
+	key := types.NamespacedName{
+		Name:      ref.Name,
+		Namespace: ref.Namespace,
+	}
+	if key.Namespace == "" {
+		key.Namespace = profile.Namespace
+	}
+	template := &hwcc.HardwareClassificationTemplate{}
+	if err := c.Get(ctx, key, template); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not load template %s", key))
+	}
+
+	profile.Spec.HardwareCharacteristics = mergeCharacteristics(
+		template.Spec.HardwareCharacteristics, profile.Spec.HardwareCharacteristics)
+	return nil
+}
+
+// mergeCharacteristics returns the template characteristics with
+// every field set in the overrides replaced, field by field. Fields
+// left empty in the overrides keep the value of the template, so a
+// zero value cannot be used to clear a template field.
+func mergeCharacteristics(template, overrides hwcc.HardwareCharacteristics) hwcc.HardwareCharacteristics {
+	merged := template.DeepCopy()
+	mergeValue(reflect.ValueOf(merged).Elem(), reflect.ValueOf(overrides.DeepCopy()).Elem())
+	return *merged
+}
+
+// apiPackage is used to only descend into our own API types. Types
+// from other packages, like resource quantities, are merged as a
+// whole.
+var apiPackage = reflect.TypeOf(hwcc.HardwareCharacteristics{}).PkgPath()
+
+func mergeValue(dst, override reflect.Value) {
+	switch {
+	case override.Kind() == reflect.Ptr:
+		if override.IsNil() {
+			return
+		}
+		if dst.IsNil() || override.Elem().Type().PkgPath() != apiPackage {
+			dst.Set(override)
+			return
+		}
+		mergeValue(dst.Elem(), override.Elem())
+	case override.Kind() == reflect.Struct && override.Type().PkgPath() == apiPackage:
+		for i := 0; i < override.NumField(); i++ {
+			mergeValue(dst.Field(i), override.Field(i))
+		}
+	case !override.IsZero():
+		dst.Set(override)
+	}
+}
+
+// setTemplateCondition records whether the template of the profile
+// was resolved, and the resulting characteristics.
+func setTemplateCondition(hwc *hwcc.HardwareClassification, templateErr error) {
+	if hwc.Spec.TemplateRef == nil {
+		removeStatusCondition(&hwc.Status.Conditions, hwcc.ConditionTemplateResolved)
+		hwc.Status.EffectiveHardwareCharacteristics = nil
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               hwcc.ConditionTemplateResolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             hwcc.ReasonTemplateFound,
+		ObservedGeneration: hwc.Generation,
+		Message:            fmt.Sprintf("using template %s", hwc.Spec.TemplateRef.Name),
+	}
+	hwc.Status.EffectiveHardwareCharacteristics = hwc.Spec.HardwareCharacteristics.DeepCopy()
+	if templateErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = hwcc.ReasonTemplateNotFound
+		if errors.Cause(templateErr) == errTemplateNamespaceNotAllowed {
+			condition.Reason = hwcc.ReasonTemplateNamespaceNotAllowed
+		}
+		condition.Message = templateErr.Error()
+		hwc.Status.EffectiveHardwareCharacteristics = nil
+	}
+	meta.SetStatusCondition(&hwc.Status.Conditions, condition)
+}
+
+// templateClassificationMapper enqueues the profiles referencing a
+// template.
+type templateClassificationMapper struct {
+	client client.Client
+}
+
+func (m *templateClassificationMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("HardwareClassification").WithName("mapper").
+		WithValues("HardwareClassificationTemplate",
+			fmt.Sprintf("%s/%s", obj.Meta.GetNamespace(), obj.Meta.GetName()))
+
+	// Profiles may reference templates from other namespaces.
+	key := templateKey(obj)
+	hwcList := hwcc.HardwareClassificationList{}
+	if err := m.client.List(context.TODO(), &hwcList, client.MatchingFields{templateRefField: key}); err != nil {
+		log.Error(err, "could not fetch hardware classification list")
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, profile := range referencingProfiles(hwcList.Items, key) {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      profile.Name,
+				Namespace: profile.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// templateClusterClassificationMapper enqueues the cluster profiles
+// referencing a template.
+type templateClusterClassificationMapper struct {
+	client client.Client
+}
+
+func (m *templateClusterClassificationMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("ClusterHardwareClassification").WithName("mapper").
+		WithValues("HardwareClassificationTemplate",
+			fmt.Sprintf("%s/%s", obj.Meta.GetNamespace(), obj.Meta.GetName()))
+
+	key := templateKey(obj)
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := m.client.List(context.TODO(), &clusterProfileList, client.MatchingFields{templateRefField: key}); err != nil {
+		log.Error(err, "could not fetch cluster hardware classification list")
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, clusterProfile := range referencingClusterProfiles(clusterProfileList.Items, key) {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{Name: clusterProfile.Name},
+		})
+	}
+	return requests
+}
+
+// templateHostsMapper enqueues the hosts the profiles referencing a
+// template apply to: every host when a cluster profile references it,
+// otherwise the hosts in the namespaces of the referencing profiles.
+type templateHostsMapper struct {
+	client client.Client
+}
+
+func (m *templateHostsMapper) Map(obj handler.MapObject) []ctrl.Request {
+	log := ctrl.Log.WithName("controllers").WithName("BareMetalHost").WithName("mapper").
+		WithValues("HardwareClassificationTemplate",
+			fmt.Sprintf("%s/%s", obj.Meta.GetNamespace(), obj.Meta.GetName()))
+
+	key := templateKey(obj)
+	clusterProfileList := hwcc.ClusterHardwareClassificationList{}
+	if err := m.client.List(context.TODO(), &clusterProfileList, client.MatchingFields{templateRefField: key}); err != nil {
+		log.Error(err, "could not fetch cluster hardware classification list")
+		return nil
+	}
+	if len(referencingClusterProfiles(clusterProfileList.Items, key)) > 0 {
+		bmhHostList := bmh.BareMetalHostList{}
+		if err := m.client.List(context.TODO(), &bmhHostList); err != nil {
+			log.Error(err, "could not fetch host list")
+			return nil
+		}
+		return hostRequests(bmhHostList.Items)
+	}
+
+	hwcList := hwcc.HardwareClassificationList{}
+	if err := m.client.List(context.TODO(), &hwcList, client.MatchingFields{templateRefField: key}); err != nil {
+		log.Error(err, "could not fetch hardware classification list")
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	namespaces := map[string]bool{}
+	for _, profile := range referencingProfiles(hwcList.Items, key) {
+		if namespaces[profile.Namespace] {
+			continue
+		}
+		namespaces[profile.Namespace] = true
+
+		bmhHostList := bmh.BareMetalHostList{}
+		if err := m.client.List(context.TODO(), &bmhHostList, client.InNamespace(profile.Namespace)); err != nil {
+			log.Error(err, "could not fetch host list")
+			return nil
+		}
+		requests = append(requests, hostRequests(bmhHostList.Items)...)
+	}
+	return requests
+}
+
+// templateKey returns the templateRefField index key of a template.
+func templateKey(obj handler.MapObject) string {
+	return obj.Meta.GetNamespace() + "/" + obj.Meta.GetName()
+}
+
+// referencingProfiles returns the profiles referencing the template
+// with the given index key.
+func referencingProfiles(profiles []hwcc.HardwareClassification, key string) []hwcc.HardwareClassification {
+	result := []hwcc.HardwareClassification{}
+	for _, profile := range profiles {
+		ref := profile.Spec.TemplateRef
+		if ref != nil && templateRefKey(ref, profile.Namespace) == key {
+			result = append(result, profile)
+		}
+	}
+	return result
+}
+
+// referencingClusterProfiles returns the cluster profiles referencing
+// the template with the given index key.
+func referencingClusterProfiles(clusterProfiles []hwcc.ClusterHardwareClassification, key string) []hwcc.ClusterHardwareClassification {
+	result := []hwcc.ClusterHardwareClassification{}
+	for _, clusterProfile := range clusterProfiles {
+		ref := clusterProfile.Spec.TemplateRef
+		if ref != nil && templateRefKey(ref, "") == key {
+			result = append(result, clusterProfile)
+		}
+	}
+	return result
+}
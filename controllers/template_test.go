@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestMergeCharacteristics(t *testing.T) {
+	template := hwcc.HardwareCharacteristics{
+		Cpu: &hwcc.Cpu{
+			Architecture: "x86_64",
+			MinimumCount: 32,
+		},
+		Ram: &hwcc.Ram{MinimumSizeGB: 128},
+		Disk: &hwcc.Disk{
+			MinimumCount: 2,
+			DiskSelector: []hwcc.DiskSelector{{HCTL: "0:0:0:0"}},
+		},
+		SystemVendor: &hwcc.SystemVendor{Manufacturer: "Dell Inc."},
+	}
+
+	testCases := []struct {
+		Scenario  string
+		Overrides hwcc.HardwareCharacteristics
+		Expected  hwcc.HardwareCharacteristics
+	}{
+		{
+			Scenario: "no-overrides",
+			Expected: template,
+		},
+		{
+			Scenario: "override-field",
+			Overrides: hwcc.HardwareCharacteristics{
+				Ram: &hwcc.Ram{MinimumSizeGB: 256},
+				Cpu: &hwcc.Cpu{MaximumCount: 64},
+			},
+			Expected: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{
+					Architecture: "x86_64",
+					MinimumCount: 32,
+					MaximumCount: 64,
+				},
+				Ram:          &hwcc.Ram{MinimumSizeGB: 256},
+				Disk:         template.Disk,
+				SystemVendor: template.SystemVendor,
+			},
+		},
+		{
+			Scenario: "override-list",
+			Overrides: hwcc.HardwareCharacteristics{
+				Disk: &hwcc.Disk{
+					DiskSelector: []hwcc.DiskSelector{{HCTL: "1:0:0:0"}},
+				},
+			},
+			Expected: hwcc.HardwareCharacteristics{
+				Cpu: template.Cpu,
+				Ram: template.Ram,
+				Disk: &hwcc.Disk{
+					MinimumCount: 2,
+					DiskSelector: []hwcc.DiskSelector{{HCTL: "1:0:0:0"}},
+				},
+				SystemVendor: template.SystemVendor,
+			},
+		},
+		{
+			Scenario: "add-characteristic",
+			Overrides: hwcc.HardwareCharacteristics{
+				Nic: &hwcc.Nic{MinimumCount: 2},
+			},
+			Expected: hwcc.HardwareCharacteristics{
+				Cpu:          template.Cpu,
+				Ram:          template.Ram,
+				Disk:         template.Disk,
+				Nic:          &hwcc.Nic{MinimumCount: 2},
+				SystemVendor: template.SystemVendor,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			merged := mergeCharacteristics(template, tc.Overrides)
+			assert.Equal(t, tc.Expected, merged)
+		})
+	}
+
+	// The template itself is never modified.
+	assert.Equal(t, 128, template.Ram.MinimumSizeGB)
+	assert.Equal(t, 0, template.Cpu.MaximumCount)
+}
+
+func TestResolveTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	template := &hwcc.HardwareClassificationTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "large",
+			Namespace: "templates",
+		},
+		Spec: hwcc.HardwareClassificationTemplateSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Cpu: &hwcc.Cpu{MinimumCount: 32},
+				Ram: &hwcc.Ram{MinimumSizeGB: 128},
+			},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, template)
+
+	profile := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "large-more-ram",
+			Namespace: "templates",
+		},
+		Spec: hwcc.HardwareClassificationSpec{
+			TemplateRef: &hwcc.TemplateReference{Name: "large"},
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Ram: &hwcc.Ram{MinimumSizeGB: 256},
+			},
+		},
+	}
+	assert.NoError(t, resolveTemplate(context.TODO(), c, profile))
+	assert.Equal(t, hwcc.HardwareCharacteristics{
+		Cpu: &hwcc.Cpu{MinimumCount: 32},
+		Ram: &hwcc.Ram{MinimumSizeGB: 256},
+	}, profile.Spec.HardwareCharacteristics)
+
+	other := &hwcc.HardwareClassification{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "site"},
+		Spec: hwcc.HardwareClassificationSpec{
+			TemplateRef: &hwcc.TemplateReference{Name: "large"},
+		},
+	}
+	assert.Error(t, resolveTemplate(context.TODO(), c, other))
+
+	// Namespaced profiles cannot read the templates of other
+	// namespaces, only cluster profiles can.
+	other.Spec.TemplateRef.Namespace = "templates"
+	err := resolveTemplate(context.TODO(), c, other)
+	assert.Equal(t, errTemplateNamespaceNotAllowed, pkgerrors.Cause(err))
+	assert.Nil(t, other.Spec.HardwareCharacteristics.Cpu)
+	profile.Spec.TemplateRef.Namespace = "templates"
+	assert.NoError(t, resolveTemplate(context.TODO(), c, profile))
+
+	clusterProfile := profileFromCluster(&hwcc.ClusterHardwareClassification{
+		Spec: hwcc.ClusterHardwareClassificationSpec{
+			HardwareClassificationSpec: hwcc.HardwareClassificationSpec{
+				TemplateRef: &hwcc.TemplateReference{Name: "large", Namespace: "templates"},
+			},
+		},
+	})
+	assert.NoError(t, resolveTemplate(context.TODO(), c, clusterProfile))
+	assert.Equal(t, template.Spec.HardwareCharacteristics, clusterProfile.Spec.HardwareCharacteristics)
+}
+
+func TestSetTemplateCondition(t *testing.T) {
+	profile := hwcc.HardwareClassification{
+		Spec: hwcc.HardwareClassificationSpec{
+			HardwareCharacteristics: hwcc.HardwareCharacteristics{
+				Ram: &hwcc.Ram{MinimumSizeGB: 256},
+			},
+		},
+	}
+
+	setTemplateCondition(&profile, nil)
+	assert.Nil(t, meta.FindStatusCondition(profile.Status.Conditions, hwcc.ConditionTemplateResolved))
+	assert.Nil(t, profile.Status.EffectiveHardwareCharacteristics)
+
+	profile.Spec.TemplateRef = &hwcc.TemplateReference{Name: "large"}
+	setTemplateCondition(&profile, nil)
+	assert.True(t, meta.IsStatusConditionTrue(profile.Status.Conditions, hwcc.ConditionTemplateResolved))
+	assert.Equal(t, &profile.Spec.HardwareCharacteristics, profile.Status.EffectiveHardwareCharacteristics)
+
+	setTemplateCondition(&profile, errors.New("not found"))
+	condition := meta.FindStatusCondition(profile.Status.Conditions, hwcc.ConditionTemplateResolved)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, hwcc.ReasonTemplateNotFound, condition.Reason)
+	}
+	assert.Nil(t, profile.Status.EffectiveHardwareCharacteristics)
+
+	setTemplateCondition(&profile, pkgerrors.Wrap(errTemplateNamespaceNotAllowed, "could not load template"))
+	condition = meta.FindStatusCondition(profile.Status.Conditions, hwcc.ConditionTemplateResolved)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, hwcc.ReasonTemplateNamespaceNotAllowed, condition.Reason)
+	}
+}
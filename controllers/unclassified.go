@@ -0,0 +1,89 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	"github.com/metal3-io/hardware-classification-controller/utils"
+)
+
+const (
+	// unclassifiedProfileName is reserved, the label of a profile with
+	// that name would be the unclassified label.
+	unclassifiedProfileName = "unclassified"
+
+	// unclassifiedLabel is set on inspected hosts matching no profile
+	// when unclassified labels are enabled.
+	unclassifiedLabel = defaultLabelName + unclassifiedProfileName
+
+	// unclassifiedEvent is the reason of the event emitted when a host
+	// is labelled as unclassified.
+	unclassifiedEvent = "Unclassified"
+)
+
+// errReservedProfileName is reported on profiles named unclassified,
+// which are never applied to hosts.
+var errReservedProfileName = errors.Errorf(
+	"the profile name %s is reserved for the unclassified label", unclassifiedProfileName)
+
+// reservedProfileName reports whether the profile is named like the
+// unclassified label. Cluster profiles have their own label prefix.
+func reservedProfileName(profile *hwcc.HardwareClassification) bool {
+	return profile.Name == unclassifiedProfileName
+}
+
+// rejectProfile records in the status of a profile with a reserved name
+// why it is not applied. The finalizer is removed, the profile labels
+// no host.
+func (hcReconciler *HardwareClassificationReconciler) rejectProfile(ctx context.Context, logger logr.Logger, hwc *hwcc.HardwareClassification) error {
+	if hasFinalizer(hwc) {
+		hwc.Finalizers = utils.FilterStringFromList(hwc.Finalizers, hwcc.Finalizer)
+		if err := hcReconciler.Update(ctx, hwc); err != nil {
+			return errors.Wrap(err, "failed to remove finalizer")
+		}
+	}
+	if !hwc.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	original := hwc.DeepCopy()
+	hwc.Status.ErrorType = hwcc.ProfileNameReserved
+	hwc.Status.ErrorMessage = errReservedProfileName.Error()
+	if equality.Semantic.DeepEqual(original.Status, hwc.Status) {
+		return nil
+	}
+	logger.Info("rejecting profile", "reason", hwc.Status.ErrorMessage)
+	err := hcReconciler.Status().Patch(ctx, hwc, client.MergeFrom(original))
+	return errors.Wrap(err, "failed to update status")
+}
+
+// setUnclassifiedLabel sets the unclassified label on hosts matching no
+// profile and removes it from the others.
+func setUnclassifiedLabel(host *bmh.BareMetalHost, enabled, unclassified bool) bool {
+	if enabled && unclassified {
+		return setLabel(host, unclassifiedLabel, "true")
+	}
+	return deleteLabel(host, unclassifiedLabel)
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	bmh "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	hwcc "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+)
+
+func TestSetUnclassifiedLabel(t *testing.T) {
+	testCases := []struct {
+		Scenario     string
+		Enabled      bool
+		Unclassified bool
+		Labelled     bool
+		Expected     bool
+	}{
+		{
+			Scenario:     "unclassified",
+			Enabled:      true,
+			Unclassified: true,
+			Expected:     true,
+		},
+		{
+			Scenario: "classified",
+			Enabled:  true,
+			Labelled: true,
+		},
+		{
+			Scenario:     "disabled",
+			Unclassified: true,
+			Labelled:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Scenario, func(t *testing.T) {
+			host := &bmh.BareMetalHost{}
+			if tc.Labelled {
+				host.Labels = map[string]string{unclassifiedLabel: "true"}
+			}
+			assert.True(t, setUnclassifiedLabel(host, tc.Enabled, tc.Unclassified))
+			assert.Equal(t, tc.Expected, hasLabel(host, unclassifiedLabel))
+		})
+	}
+}
+
+func TestReconcileUnclassifiedHosts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	host := func(name string, cpuCount int) *bmh.BareMetalHost {
+		return &bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metal3", ResourceVersion: "1"},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{CPU: bmh.CPU{Count: cpuCount}},
+			},
+		}
+	}
+	c := fake.NewFakeClientWithScheme(scheme,
+		host("small", 8),
+		host("large", 32),
+		&hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "metal3"},
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 16},
+				},
+			},
+		})
+	recorder := record.NewFakeRecorder(10)
+	r := &BareMetalHostReconciler{
+		Client:             c,
+		Log:                ctrl.Log.WithName("test"),
+		Scheme:             scheme,
+		Recorder:           recorder,
+		UnclassifiedLabels: true,
+	}
+
+	for _, name := range []string{"small", "large"} {
+		key := types.NamespacedName{Name: name, Namespace: "metal3"}
+		_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+		assert.NoError(t, err)
+	}
+
+	small := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "small", Namespace: "metal3"}, small))
+	assert.Equal(t, "true", small.Labels[unclassifiedLabel])
+	large := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Name: "large", Namespace: "metal3"}, large))
+	assert.NotContains(t, large.Labels, unclassifiedLabel)
+	assert.Contains(t, large.Labels, defaultLabelName+"large")
+
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Equal(t, "Warning Unclassified host matches no classification profile", <-recorder.Events)
+	}
+
+	// The event is only emitted when the label is set.
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "small", Namespace: "metal3"}})
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestUnclassifiedProfileName(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	// A profile named unclassified is never applied, its label would be
+	// the unclassified label.
+	c := fake.NewFakeClientWithScheme(scheme,
+		&bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "metal3", ResourceVersion: "1"},
+			Status: bmh.BareMetalHostStatus{
+				HardwareDetails: &bmh.HardwareDetails{CPU: bmh.CPU{Count: 32}},
+			},
+		},
+		&hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{Name: "unclassified", Namespace: "metal3"},
+			Spec: hwcc.HardwareClassificationSpec{
+				HardwareCharacteristics: hwcc.HardwareCharacteristics{
+					Cpu: &hwcc.Cpu{MinimumCount: 16},
+				},
+			},
+		})
+	r := &BareMetalHostReconciler{
+		Client:             c,
+		Log:                ctrl.Log.WithName("test"),
+		Scheme:             scheme,
+		Recorder:           record.NewFakeRecorder(10),
+		UnclassifiedLabels: true,
+	}
+
+	key := types.NamespacedName{Name: "large", Namespace: "metal3"}
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	stored := &bmh.BareMetalHost{}
+	assert.NoError(t, c.Get(context.TODO(), key, stored))
+	assert.Equal(t, map[string]string{unclassifiedLabel: "true"}, stored.Labels)
+	assert.Empty(t, labelledProfiles(stored))
+}
+
+func TestRejectUnclassifiedProfile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, hwcc.AddToScheme(scheme))
+	assert.NoError(t, bmh.AddToScheme(scheme))
+
+	c := fake.NewFakeClientWithScheme(scheme,
+		&bmh.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "host",
+				Namespace: "metal3",
+				Labels:    map[string]string{unclassifiedLabel: "true"},
+			},
+		},
+		&hwcc.HardwareClassification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "unclassified",
+				Namespace:  "metal3",
+				Finalizers: []string{hwcc.Finalizer},
+			},
+		})
+	r := &HardwareClassificationReconciler{
+		Client: c,
+		Log:    ctrl.Log.WithName("test"),
+		Scheme: scheme,
+	}
+
+	key := types.NamespacedName{Name: "unclassified", Namespace: "metal3"}
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+	assert.NoError(t, err)
+
+	// The unclassified host is not counted as matching the profile,
+	// which can be deleted.
+	stored := &hwcc.HardwareClassification{}
+	assert.NoError(t, c.Get(context.TODO(), key, stored))
+	assert.Empty(t, stored.Finalizers)
+	assert.Equal(t, hwcc.ProfileNameReserved, stored.Status.ErrorType)
+	assert.Equal(t, errReservedProfileName.Error(), stored.Status.ErrorMessage)
+	assert.Zero(t, stored.Status.MatchedCount)
+}
@@ -16,19 +16,26 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"time"
 
 	metal3iov1alpha1 "github.com/metal3-io/hardware-classification-controller/api/v1alpha1"
+	metal3iov1alpha2 "github.com/metal3-io/hardware-classification-controller/api/v1alpha2"
 
 	bmoapis "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 
 	"github.com/metal3-io/hardware-classification-controller/controllers"
 
+	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	// +kubebuilder:scaffold:imports
 )
@@ -42,6 +49,7 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
 	_ = metal3iov1alpha1.AddToScheme(scheme)
+	_ = metal3iov1alpha2.AddToScheme(scheme)
 	_ = bmoapis.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
@@ -50,47 +58,155 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var watchNamespace string
+	var enableFactLabels bool
+	var enableDriftLabels bool
+	var enableUnclassifiedLabels bool
+	var waitForInspection bool
+	var failureLabel string
+	var healthAddr string
+	var syncPeriod time.Duration
+	var hwcConcurrency int
+	var clusterHWCConcurrency int
+	var bmhConcurrency int
+	var inventoryConcurrency int
+	var rateLimiterBaseDelay time.Duration
+	var rateLimiterMaxDelay time.Duration
+	var rateLimiterQPS float64
+	var rateLimiterBurst int
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&watchNamespace, "namespace", "",
 		"Namespace that the controller watches to reconcile HWCC objects. If unspecified, the controller watches for HWCC objects across all namespaces.")
+	flag.BoolVar(&enableFactLabels, "enable-fact-labels", false,
+		"Enable labelling every inspected BareMetalHost with its normalised hardware facts (hwcc.metal3.io/*).")
+	flag.BoolVar(&enableDriftLabels, "enable-drift-labels", false,
+		"Enable labelling BareMetalHosts whose hardware changed since they were classified.")
+	flag.BoolVar(&enableUnclassifiedLabels, "enable-unclassified-labels", false,
+		"Enable labelling inspected BareMetalHosts matching no profile with hardwareclassification.metal3.io/unclassified.")
+	flag.BoolVar(&waitForInspection, "wait-for-inspection", false,
+		"Only label BareMetalHosts once an inspection completed, ignoring hardware details set otherwise.")
+	flag.StringVar(&failureLabel, "failure-label", controllers.DefaultFailureLabel,
+		"Label set on BareMetalHosts in error, with the error type as value.")
+	flag.StringVar(&healthAddr, "health-addr", ":9440",
+		"The address the health and readiness probe endpoints bind to.")
+	flag.DurationVar(&syncPeriod, "sync-period", 0,
+		"The minimum interval at which all watched resources are reconciled again. Defaults to 10h when unset.")
+	flag.IntVar(&hwcConcurrency, "hardware-classification-concurrency", 1,
+		"Number of HardwareClassification resources reconciled concurrently.")
+	flag.IntVar(&clusterHWCConcurrency, "cluster-hardware-classification-concurrency", 1,
+		"Number of ClusterHardwareClassification resources reconciled concurrently.")
+	flag.IntVar(&bmhConcurrency, "baremetalhost-concurrency", 1,
+		"Number of BareMetalHosts classified concurrently.")
+	flag.IntVar(&inventoryConcurrency, "hardware-inventory-concurrency", 1,
+		"Number of namespaces whose HardwareInventory is updated concurrently.")
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", 5*time.Millisecond,
+		"Delay before retrying a failed reconcile, doubled on each new failure.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", 1000*time.Second,
+		"Maximum delay before retrying a failed reconcile.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", 10,
+		"Overall number of reconciles queued per second by each controller.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", 100,
+		"Number of reconciles each controller may queue at once above rate-limiter-qps.")
+	// Logs are written as JSON at info level unless changed with the
+	// --zap-* flags, --zap-log-level=debug adds the result of every
+	// classification check.
+	logOptions := zap.Options{}
+	logOptions.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(func(o *zap.Options) {
-		o.Development = true
-	}))
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&logOptions)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "controller-leader-election-hwcc",
-		Port:               9443,
-		Namespace:          watchNamespace,
-	})
+	options := ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		HealthProbeBindAddress: healthAddr,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       "controller-leader-election-hwcc",
+		Port:                   9443,
+		Namespace:              watchNamespace,
+	}
+	if syncPeriod > 0 {
+		options.SyncPeriod = &syncPeriod
+	}
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if err = mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to add health check")
+		os.Exit(1)
+	}
+	if err = mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to add readiness check")
+		os.Exit(1)
+	}
+
+	// Each controller gets its own rate limiter, the overall limit
+	// applies per work queue.
+	controllerOptions := func(concurrency int) controller.Options {
+		return controller.Options{
+			MaxConcurrentReconciles: concurrency,
+			RateLimiter: workqueue.NewMaxOfRateLimiter(
+				workqueue.NewItemExponentialFailureRateLimiter(rateLimiterBaseDelay, rateLimiterMaxDelay),
+				&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(rateLimiterQPS), rateLimiterBurst)},
+			),
+		}
+	}
+
+	if err = controllers.SetupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "unable to set up field indexes")
+		os.Exit(1)
+	}
 	if err = (&controllers.HardwareClassificationReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("HardwareClassification"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(mgr, controllerOptions(hwcConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HardwareClassification")
 		os.Exit(1)
 	}
-	if err = (&controllers.BareMetalHostReconciler{
+	if err = (&controllers.ClusterHardwareClassificationReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("BareMetalHost"),
+		Log:    ctrl.Log.WithName("controllers").WithName("ClusterHardwareClassification"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}).SetupWithManager(mgr, controllerOptions(clusterHWCConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterHardwareClassification")
+		os.Exit(1)
+	}
+	if err = (&controllers.BareMetalHostReconciler{
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("BareMetalHost"),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           mgr.GetEventRecorderFor("hardware-classification-controller"),
+		FactLabels:         enableFactLabels,
+		DriftLabels:        enableDriftLabels,
+		UnclassifiedLabels: enableUnclassifiedLabels,
+		WaitForInspection:  waitForInspection,
+		FailureLabel:       failureLabel,
+	}).SetupWithManager(mgr, controllerOptions(bmhConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BareMetalHost")
 		os.Exit(1)
 	}
+	if err = (&controllers.HardwareInventoryReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("HardwareInventory"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr, controllerOptions(inventoryConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HardwareInventory")
+		os.Exit(1)
+	}
+	// The conversion webhook needs serving certificates, set
+	// ENABLE_WEBHOOKS=false to run the manager locally without them.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&metal3iov1alpha2.HardwareClassification{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HardwareClassification")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")